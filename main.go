@@ -1,24 +1,28 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"html/template"
 	"log"
 	"math"
+	"math/rand"
 	"net/http"
 	"strconv"
 	"strings"
 	"os"
 	"encoding/json"
 	"sort"
+	"path/filepath"
+	"sync"
 )
 
 // Структура для передачі даних у шаблони
 type PageData struct {
-	IsIndex bool
-    Results       map[string]interface{}
-    DefaultValues map[string]interface{}
-	Error   string
+	IsIndex bool                        `json:"-"`
+    Results       map[string]interface{} `json:"results,omitempty"`
+    DefaultValues map[string]interface{} `json:"default_values,omitempty"`
+	Error   string                      `json:"error,omitempty"`
 }
 
 func main() {
@@ -37,16 +41,25 @@ func main() {
 
 	// Практика 3
 	http.HandleFunc("/prac-3/task-1", prac3Task1)
+	http.HandleFunc("/prac-3/sweep", prac3SweepHandler) // API для розгортки прибутку по σ
 
 	// Практика 4
 	http.HandleFunc("/prac-4/task-1", prac4Task1)
+	http.HandleFunc("/prac-4/scenarios", prac4ScenariosHandler) // API для сценаріїв мережі
+	http.HandleFunc("/prac-4/network", prac4Network)
+	http.HandleFunc("/prac-4/cables", prac4CablesHandler) // API для каталогу кабелів
+	http.HandleFunc("/prac-4/transformers", prac4Transformers)
+	http.HandleFunc("/prac-4/protection", prac4Protection)
 
 	// Практика 5
     http.HandleFunc("/prac-5/task-1", prac5Task1)
 	http.HandleFunc("/prac-5/data", prac5DataHandler) // API для отримання списку елементів
+	http.HandleFunc("/prac-5/elements", prac5Elements)
+	http.HandleFunc("/prac-5/scheme", prac5Scheme)
 
 	// Практика 6
 	http.HandleFunc("/prac-6/task-1", prac6Task1)
+	http.HandleFunc("/prac-6/receivers", prac6ReceiversHandler) // API для каталогу типових ЕП
 
 	log.Println("Server starting on http://localhost:8080")
 	err := http.ListenAndServe(":8080", nil)
@@ -76,6 +89,10 @@ func render(w http.ResponseWriter, tmplName string, data PageData, files ...stri
 				if i >= 0 && i < len(v) {
 					return v[i]
 				}
+			case []bool:
+				if i >= 0 && i < len(v) {
+					return v[i]
+				}
 			case []interface{}:
 				if i >= 0 && i < len(v) {
 					return v[i]
@@ -99,6 +116,18 @@ func render(w http.ResponseWriter, tmplName string, data PageData, files ...stri
 			}
 			return "-"
 		},
+		// Повертає результати i-ї групи зі списку результатів (наприклад, окремого ШР),
+		// або порожню карту, якщо розрахунок ще не виконано
+		"getResGroup": func(key string, i int, results map[string]interface{}) map[string]interface{} {
+			if val, ok := results[key]; ok {
+				if list, ok := val.([]map[string]interface{}); ok {
+					if i >= 0 && i < len(list) {
+						return list[i]
+					}
+				}
+			}
+			return map[string]interface{}{}
+		},
 		"iterate": func(count int) []int {
 			var items []int
 			for i := 0; i < count; i++ {
@@ -109,6 +138,21 @@ func render(w http.ResponseWriter, tmplName string, data PageData, files ...stri
 		"add": func(a, b int) int {
 			return a + b
 		},
+		// Збирає пари ключ-значення в карту для передачі кількох параметрів у вкладений шаблон
+		"dict": func(pairs ...interface{}) (map[string]interface{}, error) {
+			if len(pairs)%2 != 0 {
+				return nil, errors.New("dict expects key-value pairs")
+			}
+			m := make(map[string]interface{}, len(pairs)/2)
+			for i := 0; i < len(pairs); i += 2 {
+				key, ok := pairs[i].(string)
+				if !ok {
+					return nil, errors.New("dict keys must be strings")
+				}
+				m[key] = pairs[i+1]
+			}
+			return m, nil
+		},
 	}
 
 	tmpl := template.New("base.html").Funcs(funcMap)
@@ -126,6 +170,34 @@ func render(w http.ResponseWriter, tmplName string, data PageData, files ...stri
 	}
 }
 
+// Допоміжна функція, що визначає, чи очікує клієнт відповідь у форматі JSON (API)
+func wantsJSON(r *http.Request) bool {
+	if r.URL.Query().Get("format") == "json" {
+		return true
+	}
+	return strings.Contains(r.Header.Get("Accept"), "application/json")
+}
+
+// Допоміжна функція для запису JSON відповіді
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// Допоміжна функція, що віддає дані сторінки або як HTML, або як JSON (для API запитів)
+func respond(w http.ResponseWriter, r *http.Request, tmplName string, data PageData, files ...string) {
+	if wantsJSON(r) {
+		status := http.StatusOK
+		if data.Error != "" {
+			status = http.StatusBadRequest
+		}
+		writeJSON(w, status, data)
+		return
+	}
+	render(w, tmplName, data, files...)
+}
+
 // Допоміжна функція для парсингу чисел з плаваючею точкою
 func getFloat(r *http.Request, key string) (float64, error) {
 	val := r.FormValue(key)
@@ -133,21 +205,31 @@ func getFloat(r *http.Request, key string) (float64, error) {
 	if val == "" {
 		return 0, fmt.Errorf("empty value for %s", key)
 	}
-	return strconv.ParseFloat(val, 64)
+	f, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return 0, err
+	}
+	// NaN та нескінченність не можуть бути вхідними даними розрахунку (і не кодуються в JSON)
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, fmt.Errorf("non-finite value for %s", key)
+	}
+	return f, nil
 }
 
-func getFloatList(r *http.Request, key string) []float64 {
+// Допоміжна функція для парсингу списку чисел (полів з однаковою назвою)
+// Повертає помилку з номером рядка для першого значення, яке не є скінченним числом
+func getFloatList(r *http.Request, key string) ([]float64, error) {
 	var result []float64
 	values := r.Form[key]
-	for _, v := range values {
-		v = strings.ReplaceAll(v, ",", ".")
-		if f, err := strconv.ParseFloat(v, 64); err == nil {
-			result = append(result, f)
-		} else {
-			result = append(result, 0)
+	for i, v := range values {
+		v = strings.TrimSpace(strings.ReplaceAll(v, ",", "."))
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, fmt.Errorf("рядок %d: %s = %q не є числом", i+1, strings.TrimSuffix(key, "[]"), values[i])
 		}
+		result = append(result, f)
 	}
-	return result
+	return result, nil
 }
 
 // Допоміжна функція для заокруглення чисел
@@ -354,11 +436,222 @@ func prac2Task1(w http.ResponseWriter, r *http.Request) {
 	render(w, "prac_2_task_1", data, "templates/prac_2_task_1.html")
 }
 
+// Результат розрахунку прибутку сонячної електростанції для одного значення σ
+type solarProfitPoint struct {
+	Sigma   float64 `json:"sigma"`   // σ, МВт
+	Share   float64 `json:"share"`   // частка енергії без небалансу, %
+	Revenue float64 `json:"revenue"` // виручка за енергію без небалансу, тис. грн
+	Penalty float64 `json:"penalty"` // штраф за енергію з небалансом, тис. грн
+	Profit  float64 `json:"profit"`  // прибуток, тис. грн
+}
+
+// Функція для розрахунку прибутку
+// Використовуємо math.Erf для інтегрування нормального розподілу
+func calculateSolarProfit(Pc, B, sigma float64) solarProfitPoint {
+	// Межі інтегрування: Pc - 0.05*Pc до Pc + 0.05*Pc
+	// Це симетричний інтервал навколо середнього (Pc).
+	// Інтеграл від PDF нормального розподілу в межах [μ - δ, μ + δ] дорівнює erf(δ / (σ * sqrt(2)))
+	delta := 0.05 * Pc
+	qW := math.Erf(delta / (sigma * math.Sqrt(2)))
+
+	// Розрахуємо прибуток (частка без небалансу)
+	W_success := Pc * 24 * qW
+	P_success := W_success * B
+
+	// Розрахуємо штраф (частка з небалансом)
+	W_imbalance := Pc * 24 * (1 - qW)
+	Penalty := W_imbalance * B
+
+	return solarProfitPoint{
+		Sigma:   sigma,
+		Share:   qW * 100,
+		Revenue: P_success,
+		Penalty: Penalty,
+		Profit:  P_success - Penalty,
+	}
+}
+
+// Максимальна кількість точок розгортки, щоб користувач випадково не "поклав" сервер
+const maxSweepPoints = 500
+
+// Метод, що обчислює прибуток для діапазону σ від from до to з кроком step
+func sweepSolarProfit(Pc, B, from, to, step float64) ([]solarProfitPoint, error) {
+	// NaN та нескінченності проходять звичайні порівняння, тому відкидаємо їх окремо
+	for _, v := range []float64{Pc, B, from, to, step} {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, fmt.Errorf("параметри розгортки мають бути скінченними числами")
+		}
+	}
+	if Pc <= 0 {
+		return nil, fmt.Errorf("Pc має бути більше 0")
+	}
+	if from <= 0 || step <= 0 {
+		return nil, fmt.Errorf("σ та крок розгортки мають бути більше 0")
+	}
+	if to < from {
+		return nil, fmt.Errorf("кінцеве значення σ має бути не менше за початкове")
+	}
+
+	// Рахуємо кількість точок через індекс, щоб не накопичувати похибку додавання кроку.
+	// Межу перевіряємо ще для дробового значення, бо перетворення великого числа в int дає сміття
+	intervals := math.Floor((to-from)/step + 1e-9)
+	if math.IsInf(intervals, 0) || intervals+1 > maxSweepPoints {
+		return nil, fmt.Errorf("забагато точок розгортки (%g), максимум %d", intervals+1, maxSweepPoints)
+	}
+	count := int(intervals) + 1
+
+	points := make([]solarProfitPoint, 0, count)
+	for i := 0; i < count; i++ {
+		sigma := from + float64(i)*step
+		p := calculateSolarProfit(Pc, B, sigma)
+		points = append(points, solarProfitPoint{
+			Sigma:   round(p.Sigma, 4),
+			Share:   round(p.Share, 2),
+			Revenue: round(p.Revenue, 2),
+			Penalty: round(p.Penalty, 2),
+			Profit:  round(p.Profit, 2),
+		})
+	}
+	return points, nil
+}
+
+// Метод, що будує SVG графік прибутку, штрафу (ліва вісь, тис. грн)
+// та частки енергії без небалансу (права вісь, %) в залежності від σ
+func profitChartSVG(points []solarProfitPoint) string {
+	const width, height = 760.0, 420.0
+	const left, right, top, bottom = 70.0, 70.0, 40.0, 60.0
+	plotW := width - left - right
+	plotH := height - top - bottom
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" viewBox="0 0 %.0f %.0f" font-family="sans-serif" font-size="12">`,
+		width, height, width, height)
+	sb.WriteString(`<rect width="100%" height="100%" fill="white"/>`)
+	if len(points) == 0 {
+		sb.WriteString(`</svg>`)
+		return sb.String()
+	}
+
+	// Межі осей
+	minX, maxX := points[0].Sigma, points[len(points)-1].Sigma
+	if maxX == minX {
+		maxX = minX + 1
+	}
+	minY, maxY := 0.0, 0.0
+	for _, p := range points {
+		minY = math.Min(minY, math.Min(p.Profit, p.Penalty))
+		maxY = math.Max(maxY, math.Max(p.Profit, p.Penalty))
+	}
+	if maxY == minY {
+		maxY = minY + 1
+	}
+
+	xPos := func(v float64) float64 { return left + (v-minX)/(maxX-minX)*plotW }
+	yPos := func(v float64) float64 { return top + plotH - (v-minY)/(maxY-minY)*plotH }
+	yShare := func(v float64) float64 { return top + plotH - v/100*plotH }
+
+	// Сітка та підписи осей
+	const ticks = 5
+	for i := 0; i <= ticks; i++ {
+		f := float64(i) / ticks
+		xv := minX + f*(maxX-minX)
+		yv := minY + f*(maxY-minY)
+		x := xPos(xv)
+		y := yPos(yv)
+		fmt.Fprintf(&sb, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#e5e5e5"/>`, left, y, left+plotW, y)
+		fmt.Fprintf(&sb, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#e5e5e5"/>`, x, top, x, top+plotH)
+		fmt.Fprintf(&sb, `<text x="%.1f" y="%.1f" text-anchor="end">%.1f</text>`, left-6, y+4, yv)
+		fmt.Fprintf(&sb, `<text x="%.1f" y="%.1f" text-anchor="start">%.0f%%</text>`, left+plotW+6, yShare(f*100)+4, f*100)
+		fmt.Fprintf(&sb, `<text x="%.1f" y="%.1f" text-anchor="middle">%.2f</text>`, x, top+plotH+18, xv)
+	}
+	fmt.Fprintf(&sb, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="none" stroke="#333"/>`, left, top, plotW, plotH)
+	if minY < 0 {
+		fmt.Fprintf(&sb, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#999" stroke-dasharray="4 3"/>`, left, yPos(0), left+plotW, yPos(0))
+	}
+	fmt.Fprintf(&sb, `<text x="%.1f" y="%.1f" text-anchor="middle">σ, МВт</text>`, left+plotW/2, height-15)
+	fmt.Fprintf(&sb, `<text x="15" y="%.1f" text-anchor="middle" transform="rotate(-90 15 %.1f)">тис. грн</text>`, top+plotH/2, top+plotH/2)
+	fmt.Fprintf(&sb, `<text x="%.1f" y="%.1f" text-anchor="middle" transform="rotate(90 %.1f %.1f)">частка без небалансу, %%</text>`,
+		width-15, top+plotH/2, width-15, top+plotH/2)
+
+	// Лінії графіків
+	series := []struct {
+		name  string
+		color string
+		y     func(p solarProfitPoint) float64
+	}{
+		{"Прибуток", "#198754", func(p solarProfitPoint) float64 { return yPos(p.Profit) }},
+		{"Штраф", "#dc3545", func(p solarProfitPoint) float64 { return yPos(p.Penalty) }},
+		{"Частка без небалансу", "#0d6efd", func(p solarProfitPoint) float64 { return yShare(p.Share) }},
+	}
+	for i, s := range series {
+		coords := make([]string, 0, len(points))
+		for _, p := range points {
+			coords = append(coords, fmt.Sprintf("%.1f,%.1f", xPos(p.Sigma), s.y(p)))
+		}
+		fmt.Fprintf(&sb, `<polyline fill="none" stroke="%s" stroke-width="2" points="%s"/>`, s.color, strings.Join(coords, " "))
+
+		// Легенда
+		lx := left + float64(i)*200
+		fmt.Fprintf(&sb, `<line x1="%.1f" y1="20" x2="%.1f" y2="20" stroke="%s" stroke-width="3"/>`, lx, lx+25, s.color)
+		fmt.Fprintf(&sb, `<text x="%.1f" y="24">%s</text>`, lx+30, s.name)
+	}
+
+	sb.WriteString(`</svg>`)
+	return sb.String()
+}
+
 // Шлях, що обробляє перше завдання третьої практичної роботи
 func prac3Task1(w http.ResponseWriter, r *http.Request) {
-	data := PageData{IsIndex: false}
+	// Значення за замовчуванням
+	defaultValues := map[string]interface{}{
+		"Pc":         5.0,
+		"Q1":         1.0,
+		"Q2":         0.25,
+		"B":          7.0,
+		"sigma_from": 0.1,
+		"sigma_to":   1.5,
+		"sigma_step": 0.05,
+	}
+	data := PageData{IsIndex: false, DefaultValues: defaultValues}
 
 	if r.Method == http.MethodPost {
+		// Режим розгортки: рахуємо прибуток для діапазону σ
+		if r.FormValue("mode") == "sweep" {
+			Pc, err1 := getFloat(r, "Pc")
+			B, err2 := getFloat(r, "B")
+			from, err3 := getFloat(r, "sigma_from")
+			to, err4 := getFloat(r, "sigma_to")
+			step, err5 := getFloat(r, "sigma_step")
+
+			if err1 != nil || err2 != nil || err3 != nil || err4 != nil || err5 != nil {
+				data.Error = "Bad values: check inputs"
+				respond(w, r, "prac_3_task_1", data, "templates/prac_3_task_1.html")
+				return
+			}
+
+			defaultValues["Pc"] = Pc
+			defaultValues["B"] = B
+			defaultValues["sigma_from"] = from
+			defaultValues["sigma_to"] = to
+			defaultValues["sigma_step"] = step
+
+			points, err := sweepSolarProfit(Pc, B, from, to, step)
+			if err != nil {
+				data.Error = err.Error()
+				respond(w, r, "prac_3_task_1", data, "templates/prac_3_task_1.html")
+				return
+			}
+
+			data.Results = map[string]interface{}{
+				"sweep": points,
+				"chart": template.HTML(profitChartSVG(points)),
+				"sweep_api": fmt.Sprintf("/prac-3/sweep?Pc=%g&B=%g&sigma_from=%g&sigma_to=%g&sigma_step=%g",
+					Pc, B, from, to, step),
+			}
+			respond(w, r, "prac_3_task_1", data, "templates/prac_3_task_1.html")
+			return
+		}
+
 		// Отримання користувацього вводу
 		Pc, err1 := getFloat(r, "Pc")
 		q1, err2 := getFloat(r, "Q1")
@@ -367,39 +660,24 @@ func prac3Task1(w http.ResponseWriter, r *http.Request) {
 
 		if err1 != nil || err2 != nil || err3 != nil || err4 != nil {
 			data.Error = "Bad values: check inputs"
-			render(w, "prac_3_task_1", data, "templates/prac_3_task_1.html")
+			respond(w, r, "prac_3_task_1", data, "templates/prac_3_task_1.html")
 			return
 		}
 
+		defaultValues["Pc"] = Pc
+		defaultValues["Q1"] = q1
+		defaultValues["Q2"] = q2
+		defaultValues["B"] = B
+
 		// Якщо q2 більше, то це не має сенсу, сповіщаємо про помилку
 		if q2 >= q1 {
 			data.Error = "σ2 має бути менше за σ1."
-			render(w, "prac_3_task_1", data, "templates/prac_3_task_1.html")
+			respond(w, r, "prac_3_task_1", data, "templates/prac_3_task_1.html")
 			return
 		}
 
-		// Функція для розрахунку прибутку
-		// Використовуємо math.Erf для інтегрування нормального розподілу
-		calculateProfit := func(sigma float64) float64 {
-			// Межі інтегрування: Pc - 0.05*Pc до Pc + 0.05*Pc
-			// Це симетричний інтервал навколо середнього (Pc).
-			// Інтеграл від PDF нормального розподілу в межах [μ - δ, μ + δ] дорівнює erf(δ / (σ * sqrt(2)))
-			delta := 0.05 * Pc
-			qW := math.Erf(delta / (sigma * math.Sqrt(2)))
-
-			// Розрахуємо прибуток (частка без небалансу)
-			W_success := Pc * 24 * qW
-			P_success := W_success * B
-
-			// Розрахуємо штраф (частка з небалансом)
-			W_imbalance := Pc * 24 * (1 - qW)
-			Penalty := W_imbalance * B
-
-			return P_success - Penalty
-		}
-
-		res1 := calculateProfit(q1)
-		res2 := calculateProfit(q2)
+		res1 := calculateSolarProfit(Pc, B, q1).Profit
+		res2 := calculateSolarProfit(Pc, B, q2).Profit
 
 		// Заносимо результати
 		data.Results = map[string]interface{}{
@@ -410,480 +688,4422 @@ func prac3Task1(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	render(w, "prac_3_task_1", data, "templates/prac_3_task_1.html")
+	respond(w, r, "prac_3_task_1", data, "templates/prac_3_task_1.html")
 }
 
-// Метод, що читає дані про кабеля з файлу
-func getJek(index int, Tm float64) (float64, error) {
-	file, err := os.Open("./instance/prac_4_cabels_data.json")
+// API Handler для розгортки прибутку по σ
+// Повертає таблицю у форматі JSON, або графік, якщо передано format=svg
+func prac3SweepHandler(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	Pc, err1 := getFloat(r, "Pc")
+	B, err2 := getFloat(r, "B")
+	from, err3 := getFloat(r, "sigma_from")
+	to, err4 := getFloat(r, "sigma_to")
+	step, err5 := getFloat(r, "sigma_step")
+
+	if err1 != nil || err2 != nil || err3 != nil || err4 != nil || err5 != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Bad values: check inputs"})
+		return
+	}
+
+	points, err := sweepSolarProfit(Pc, B, from, to, step)
 	if err != nil {
-		return 0, err
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
 	}
-	defer file.Close()
 
-	var data map[string][]float64
-	if err := json.NewDecoder(file).Decode(&data); err != nil {
-		return 0, err
+	if r.FormValue("format") == "svg" {
+		w.Header().Set("Content-Type", "image/svg+xml")
+		w.Write([]byte(profitChartSVG(points)))
+		return
 	}
 
-	var key string
-	if Tm >= 1000 && Tm <= 3000 {
-		key = "1000-3000"
-	} else if Tm > 3000 && Tm <= 5000 {
-		key = "3000-5000"
-	} else if Tm > 5000 {
-		key = "5000+"
-	} else {
-		return 0, fmt.Errorf("Tm out of range")
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"Pc":     Pc,
+		"B":      B,
+		"points": points,
+	})
+}
+
+// Смуга таблиці економічної густини струму за часом використання максимуму навантаження Tm
+type jekBand struct {
+	TmMin float64   `json:"tm_min"`
+	TmMax *float64  `json:"tm_max,omitempty"` // відсутня верхня межа означає "і більше"
+	TmRef *float64  `json:"tm_ref,omitempty"` // вузол інтерполяції (за замовчуванням - середина смуги)
+	Jek   []float64 `json:"jek"`              // густина струму для кожного типу кабеля, А/мм^2
+}
+
+// Назва смуги для повідомлень, наприклад "1000-3000" або "5000+"
+func (b jekBand) label() string {
+	if b.TmMax == nil {
+		return fmt.Sprintf("%g+", b.TmMin)
 	}
+	return fmt.Sprintf("%g-%g", b.TmMin, *b.TmMax)
+}
 
-	if vals, ok := data[key]; ok {
-		if index >= 0 && index < len(vals) {
-			return vals[index], nil
-		}
+// Вузол інтерполяції смуги
+func (b jekBand) ref() float64 {
+	if b.TmRef != nil {
+		return *b.TmRef
 	}
-	return 0, fmt.Errorf("data not found for index %d", index)
+	if b.TmMax == nil {
+		return b.TmMin
+	}
+	return (b.TmMin + *b.TmMax) / 2
 }
 
-// Метод, що "заокруглює" значення перерізу кабеля (шукає найближче стандартне)
-func getCrossSection(value float64) float64 {
-	crossSections := []float64{10, 16, 25, 35, 50, 70, 95, 120, 150, 185, 240}
-	closest := crossSections[0]
-	minDiff := math.Abs(value - closest)
+// Таблиця економічної густини струму
+type jekTable struct {
+	Interpolate bool      `json:"interpolate"` // режим за замовчуванням: лінійна інтерполяція між смугами
+	Bands       []jekBand `json:"bands"`
+}
 
-	for _, s := range crossSections {
-		diff := math.Abs(value - s)
-		if diff < minDiff {
-			minDiff = diff
-			closest = s
-		}
-	}
-	return closest
+// Помилка, що виникає, коли Tm не потрапляє в жодну смугу таблиці
+type jekRangeError struct {
+	Tm    float64  `json:"tm"`
+	Min   float64  `json:"min"`
+	Max   *float64 `json:"max,omitempty"`
+	Bands []string `json:"bands"`
 }
 
-// Шлях, що обробляє четверту практичну роботу
-func prac4Task1(w http.ResponseWriter, r *http.Request) {
-	// Значення за замовчуванням
-	defaultValues := map[string]interface{}{
-		"Ik": 2500.0,
-		"tf": 2.5,
-		"Sm": 1300.0,
-		"Tm": 4000.0,
-		"Sk": 200.0,
+func (e *jekRangeError) Error() string {
+	upper := "∞"
+	if e.Max != nil {
+		upper = fmt.Sprintf("%g", *e.Max)
 	}
-	data := PageData{
-		IsIndex:       false,
-		DefaultValues: defaultValues,
+	return fmt.Sprintf("Tm=%g год поза межами таблиці: підтримуваний діапазон %g..%s год (смуги: %s)",
+		e.Tm, e.Min, upper, strings.Join(e.Bands, ", "))
+}
+
+// Метод, що читає таблицю економічної густини струму з файлу
+func getJekTable() (jekTable, error) {
+	var table jekTable
+	file, err := os.Open("./instance/prac_4_cabels_data.json")
+	if err != nil {
+		return table, err
 	}
+	defer file.Close()
 
-	if r.Method == http.MethodPost {
-		cabelStr := r.FormValue("cabel")
-		cabel, errC := strconv.Atoi(cabelStr)
-		Ik, err1 := getFloat(r, "Ik")
-		tf, err2 := getFloat(r, "tf")
-		Sm, err3 := getFloat(r, "Sm")
-		Tm, err4 := getFloat(r, "Tm")
-		Sk, err5 := getFloat(r, "Sk")
+	if err := json.NewDecoder(file).Decode(&table); err != nil {
+		return table, err
+	}
+	if len(table.Bands) == 0 {
+		return table, fmt.Errorf("jek table has no bands")
+	}
+	sort.Slice(table.Bands, func(i, j int) bool { return table.Bands[i].TmMin < table.Bands[j].TmMin })
+	return table, nil
+}
 
-		if errC != nil || err1 != nil || err2 != nil || err3 != nil || err4 != nil || err5 != nil {
-			data.Error = "Bad values: check inputs"
-			render(w, "prac_4_task_1", data, "templates/prac_4_task_1.html")
-			return
+// Метод, що шукає економічну густину струму для типу кабеля index та часу Tm
+// Смуга включає верхню межу (Tm_min < Tm <= Tm_max), перша смуга включає також нижню межу
+// Якщо interpolate = true, значення лінійно інтерполюється між вузлами сусідніх смуг
+func (t jekTable) lookup(index int, Tm float64, interpolate bool) (float64, error) {
+	band := -1
+	for i, b := range t.Bands {
+		above := Tm > b.TmMin || (i == 0 && Tm == b.TmMin)
+		below := b.TmMax == nil || Tm <= *b.TmMax
+		if above && below {
+			band = i
+			break
+		}
+	}
+	if band == -1 {
+		rangeErr := &jekRangeError{Tm: Tm, Min: t.Bands[0].TmMin, Max: t.Bands[len(t.Bands)-1].TmMax}
+		for _, b := range t.Bands {
+			rangeErr.Bands = append(rangeErr.Bands, b.label())
 		}
+		return 0, rangeErr
+	}
 
-		// Оновлюємо значення за замовчуванням на введені користувачем
-		data.DefaultValues = map[string]interface{}{
-			"Ik": Ik,
-			"tf": tf,
-			"Sm": Sm,
-			"Tm": Tm,
-			"Sk": Sk,
+	value := func(i int) (float64, error) {
+		vals := t.Bands[i].Jek
+		if index < 0 || index >= len(vals) {
+			return 0, fmt.Errorf("data not found for index %d", index)
 		}
+		return vals[index], nil
+	}
 
-		// 1
-		// Розрахунковий струм для нормального і післяаварійного режимів
-		Im := (Sm / 2) / (math.Sqrt(3) * 10)
-		Im_pa := 2 * Im
+	if !interpolate {
+		return value(band)
+	}
 
-		// Отримуємо економічну густину струму
-		jek, errJ := getJek(cabel, Tm)
-		if errJ != nil {
-			data.Error = "Cable data error: " + errJ.Error()
-			render(w, "prac_4_task_1", data, "templates/prac_4_task_1.html")
-			return
-		}
+	// Шукаємо сусідні вузли інтерполяції; за межами крайніх вузлів значення не змінюється
+	lower, upper := band, band
+	if Tm < t.Bands[band].ref() && band > 0 {
+		lower = band - 1
+	} else if Tm > t.Bands[band].ref() && band < len(t.Bands)-1 {
+		upper = band + 1
+	}
+	v0, err := value(lower)
+	if err != nil {
+		return 0, err
+	}
+	v1, err := value(upper)
+	if err != nil {
+		return 0, err
+	}
+	x0, x1 := t.Bands[lower].ref(), t.Bands[upper].ref()
+	if lower == upper || x1 == x0 {
+		return v0, nil
+	}
+	return v0 + (v1-v0)*(Tm-x0)/(x1-x0), nil
+}
 
-		// Рахуємо економічний переріз
-		sek := Im / jek
-		// Шукаємо мінімальний переріз
-		s_min := (Ik * math.Sqrt(tf)) / 92
-		// На основі мінімального перерізу шукаємо кабель з потрібним перерізом
-		s := getCrossSection(s_min)
+// Метод, що читає дані про кабеля з файлу та повертає економічну густину струму
+func getJek(index int, Tm float64, interpolate bool) (float64, error) {
+	table, err := getJekTable()
+	if err != nil {
+		return 0, err
+	}
+	return table.lookup(index, Tm, interpolate)
+}
 
-		// 2
-		// Рауємо опори елементів
-		Xc := math.Pow(10.5, 2) / Sk
-		Xt := (10.5 / 100) * (math.Pow(10.5, 2) / 6.3)
-		// Сумарний опір
-		Xe := Xc + Xt
-		// Початкове діюче значення струму трифазного КЗ
-		Ip0 := 10.5 / (math.Sqrt(3) * Xe)
+// Стандартний переріз кабеля 10 кВ з його параметрами
+type cableSection struct {
+	S      float64 `json:"s"`       // переріз, мм^2
+	R0     float64 `json:"r0"`      // питомий активний опір, Ом/км
+	X0     float64 `json:"x0"`      // питомий реактивний опір, Ом/км
+	Iallow float64 `json:"i_allow"` // тривало допустимий струм, А
+}
 
-		// 3
-		// Сталі дані, передані з підстанції
-		Rcn := 10.65
-		Xcn := 24.02
-		Rcmin := 34.88
-		Xcmin := 65.68
-		Uk_max := 11.1
-		Uvn := 115.0
-		Unn := 11.0
-		Snomt := 6.3
-
-		// Розрахуємо реактивний опір силового трансформатора
-		Xt_tr := (Uk_max * math.Pow(Uvn, 2)) / (100 * Snomt)
-
-		// Розрахуємо опори на шинах 10 кВ в нормальному та мінімальному режимах
-		Rsh := Rcn
-		Xsh := Xcn + Xt_tr
-		Zsh := math.Sqrt(math.Pow(Rsh, 2) + math.Pow(Xsh, 2))
-
-		Rshmin := Rcmin
-		Xshmin := Xcmin + Xt_tr
-		Zshmin := math.Sqrt(math.Pow(Rshmin, 2) + math.Pow(Xshmin, 2))
-
-		// Розраховуємо струми трифазного та двофазного КЗ на шинах 10 кВ
-		Ish_3 := (Uvn * 1000) / (math.Sqrt(3) * Zsh)
-		Ish_2 := Ish_3 * math.Sqrt(3) / 2
-
-		Ish_min_3 := (Uvn * 1000) / (math.Sqrt(3) * Zshmin)
-		Ish_min_2 := Ish_min_3 * math.Sqrt(3) / 2
-
-		// Розраховуємо коефіцієнт приведення
-		kpr := math.Pow(Unn, 2) / math.Pow(Uvn, 2)
-
-		// Розраховуємо опори на шинах 10 кВ в нормальному
-		// та мінімальному режимах і заносимо їх в карту вставок
-		Rshn := Rsh * kpr
-		Xshn := Xsh * kpr
-		Zshn := math.Sqrt(math.Pow(Rshn, 2) + math.Pow(Xshn, 2))
-
-		Rshn_min := Rshmin * kpr
-		Xshn_min := Xshmin * kpr
-		Zshn_min := math.Sqrt(math.Pow(Rshn_min, 2) + math.Pow(Xshn_min, 2))
-
-		// Розраховуємо дійсні струми трифазного та двофазного КЗ
-		Ishn_3 := (Unn * 1000) / (math.Sqrt(3) * Zshn)
-		Ishn_2 := Ishn_3 * math.Sqrt(3) / 2
-
-		Ishn_min_3 := (Unn * 1000) / (math.Sqrt(3) * Zshn_min)
-		Ishn_min_2 := Ishn_min_3 * math.Sqrt(3) / 2
-
-		// Розрахунок струмів короткого замикання відхідних ліній 10 кВ
-		R0 := 0.64
-		X0 := 0.363
-		// Знайдемо резистанси та реактанси відрізка з найбільшим опором
-		Il := 0.2 + 0.35 + 0.2 + 0.6 + 2 + 2.55 + 3.37 + 3.1
-		Rl := Il * R0
-		Xl := Il * X0
-
-		// Розрахуємо опори в нормальному та мінімальному режимах
-		Ren := Rl + Rshn
-		Xen := Xl + Xshn
-		Zen := math.Sqrt(math.Pow(Ren, 2) + math.Pow(Xen, 2))
-
-		Ren_min := Rl + Rshn_min
-		Xen_min := Xl + Xshn_min
-		Zen_min := math.Sqrt(math.Pow(Ren_min, 2) + math.Pow(Xen_min, 2))
-
-		// Розрахуємо струми трифазного і двофазного КЗ
-		Iln_3 := (Unn * 1000) / (math.Sqrt(3) * Zen)
-		Iln_2 := Iln_3 * math.Sqrt(3) / 2
-
-		Iln_min_3 := (Unn * 1000) / (math.Sqrt(3) * Zen_min)
-		Iln_min_2 := Iln_min_3 * math.Sqrt(3) / 2
+// Таблиця поправкових коефіцієнтів (значення аргументу -> коефіцієнт)
+type deratingTable struct {
+	Points  []float64 `json:"points"`
+	Factors []float64 `json:"factors"`
+}
 
-		// Заносимо усі результати у список
-		data.Results = map[string]interface{}{
-			"sek":        round(sek, 2),
-			"s":          s,
-			"Im":         round(Im, 2),
-			"Im_pa":      round(Im_pa, 2),
-			"Ip0":        round(Ip0, 2),
-			"Ish_3":      round(Ish_3, 2),
-			"Ish_2":      round(Ish_2, 2),
-			"Ish_min_3":  round(Ish_min_3, 2),
-			"Ish_min_2":  round(Ish_min_2, 2),
-			"Ishn_3":     round(Ishn_3, 2),
-			"Ishn_2":     round(Ishn_2, 2),
-			"Ishn_min_3": round(Ishn_min_3, 2),
-			"Ishn_min_2": round(Ishn_min_2, 2),
-			"Iln_3":      round(Iln_3, 2),
-			"Iln_2":      round(Iln_2, 2),
-			"Iln_min_3":  round(Iln_min_3, 2),
-			"Iln_min_2":  round(Iln_min_2, 2),
-		}
-	}
-	render(w, "prac_4_task_1", data, "templates/prac_4_task_1.html")
-}
-
-// Метод, що читає дані елементів ЕПС з JSON файлу
-func getPrac5Data() (map[string][]float64, error) {
-	file, err := os.Open("./instance/prac_5_data.json")
+// Метод, що шукає поправковий коефіцієнт з лінійною інтерполяцією між точками таблиці
+func (t deratingTable) lookup(x float64) (float64, error) {
+	n := len(t.Points)
+	if n == 0 || n != len(t.Factors) {
+		return 0, fmt.Errorf("derating table is empty or malformed")
+	}
+	if x < t.Points[0] || x > t.Points[n-1] {
+		return 0, fmt.Errorf("value %g is out of table range [%g; %g]", x, t.Points[0], t.Points[n-1])
+	}
+	for i := 1; i < n; i++ {
+		if x <= t.Points[i] {
+			x0, x1 := t.Points[i-1], t.Points[i]
+			f0, f1 := t.Factors[i-1], t.Factors[i]
+			return f0 + (f1-f0)*(x-x0)/(x1-x0), nil
+		}
+	}
+	return t.Factors[0], nil
+}
+
+// Метод, що читає стандартні перерізи кабеля 10 кВ з паперовою ізоляцією для заданого матеріалу жил
+func getCableSections(material string) ([]cableSection, error) {
+	file, err := os.Open("./instance/prac_4_cable_sections.json")
 	if err != nil {
 		return nil, err
 	}
 	defer file.Close()
 
-	var data map[string][]float64
+	var data map[string][]cableSection
 	if err := json.NewDecoder(file).Decode(&data); err != nil {
 		return nil, err
 	}
-	return data, nil
-}
-
-// API Handler для отримання списку елементів
-func prac5DataHandler(w http.ResponseWriter, r *http.Request) {
-	data, err := getPrac5Data()
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	keys := make([]string, 0, len(data))
-	for k := range data {
-		keys = append(keys, k)
+	sections, ok := data[material]
+	if !ok || len(sections) == 0 {
+		return nil, fmt.Errorf("no cable sections for material %s", material)
 	}
+	sort.Slice(sections, func(i, j int) bool { return sections[i].S < sections[j].S })
+	return sections, nil
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(keys)
+// Переріз кабеля з каталогу
+type catalogSection struct {
+	S       float64 `json:"s"`        // переріз, мм^2
+	R0      float64 `json:"r0"`       // питомий активний опір, Ом/км (береться з таблиці стандартних перерізів)
+	X0      float64 `json:"x0"`       // питомий реактивний опір, Ом/км (якщо не задано - з таблиці стандартних перерізів)
+	IGround float64 `json:"i_ground"` // тривало допустимий струм при прокладанні в землі, А (0 - не допускається)
+	IAir    float64 `json:"i_air"`    // тривало допустимий струм при прокладанні на повітрі, А (0 - не допускається)
 }
 
-// Шлях, що обробляє п'яту практичну роботу
-func prac5Task1(w http.ResponseWriter, r *http.Request) {
-	data := PageData{IsIndex: false}
+// Марка кабеля (проводу) з каталогу
+type catalogCable struct {
+	ID         string           `json:"id"`
+	Name       string           `json:"name"`
+	Material   string           `json:"material"`   // Cu або Al
+	Insulation string           `json:"insulation"` // bare, paper або xlpe
+	TypeIndex  int              `json:"type_index"` // індекс типу кабеля з prac_4_cabels_data.json
+	Ukv        float64          `json:"u_kv"`
+	Sections   []catalogSection `json:"sections"`
+}
 
-	if r.Method == http.MethodPost {
-		// Отримання користувацього вводу
-		r.ParseForm()
-		quantitiesStr := r.Form["quantity[]"]
-		elements := r.Form["element[]"]
-		Zpera, err1 := getFloat(r, "Zpera")
-		Zperp, err2 := getFloat(r, "Zperp")
+// Спосіб прокладання кабеля
+type layingMethod struct {
+	Name   string  `json:"name"`
+	Base   string  `json:"base"`   // ground або air: яка колонка допустимих струмів використовується
+	Factor float64 `json:"factor"` // поправковий коефіцієнт на спосіб прокладання
+}
 
-		if err1 != nil || err2 != nil {
-			data.Error = "Bad values: check inputs"
-			render(w, "prac_5_task_1", data, "templates/prac_5_task_1.html")
-			return
-		}
+// Каталог кабелів з поправковими коефіцієнтами
+type cableCatalog struct {
+	ConductorTemperature map[string]float64                  `json:"conductor_temperature"`
+	Laying               map[string]layingMethod             `json:"laying"`
+	Ambient              map[string]map[string]deratingTable `json:"ambient"`  // ізоляція -> ground/air -> температура
+	Grouping             map[string]deratingTable            `json:"grouping"` // ground/air -> кількість кабелів
+	Cables               []catalogCable                      `json:"cables"`
+}
 
-		pracData, err := getPrac5Data()
-		if err != nil {
-			data.Error = "Error reading data file"
-			render(w, "prac_5_task_1", data, "templates/prac_5_task_1.html")
-			return
-		}
+// Поправкові коефіцієнти до тривало допустимого струму
+type cableDerating struct {
+	Laying  string  `json:"laying"`
+	Kt      float64 `json:"k_t"`      // на температуру середовища
+	Kn      float64 `json:"k_n"`      // на кількість кабелів у групі
+	Klaying float64 `json:"k_laying"` // на спосіб прокладання
+	K       float64 `json:"k"`        // загальний коефіцієнт
+	Warning string  `json:"warning,omitempty"`
+}
 
-		var woc_sum, tvoc_num, max_t_plan float64
+// Метод, що читає каталог кабелів з файлу
+func getCableCatalog() (cableCatalog, error) {
+	var catalog cableCatalog
+	file, err := os.Open("./instance/prac_4_cable_catalog.json")
+	if err != nil {
+		return catalog, err
+	}
+	defer file.Close()
 
-		for i, el := range elements {
-			if i >= len(quantitiesStr) {
-				break
-			}
-			q, err := strconv.Atoi(quantitiesStr[i])
+	if err := json.NewDecoder(file).Decode(&catalog); err != nil {
+		return catalog, err
+	}
+	// Питомий активний опір залежить лише від матеріалу та перерізу жил,
+	// тому заповнюємо його з таблиці стандартних перерізів, а не дублюємо для кожної марки.
+	// Так само беремо з неї реактивний опір, якщо для марки він не заданий окремо
+	standard := make(map[string]map[float64]cableSection)
+	for i := range catalog.Cables {
+		cable := &catalog.Cables[i]
+		table, ok := standard[cable.Material]
+		if !ok {
+			sections, err := getCableSections(cable.Material)
 			if err != nil {
+				return catalog, err
+			}
+			table = make(map[float64]cableSection, len(sections))
+			for _, cs := range sections {
+				table[cs.S] = cs
+			}
+			standard[cable.Material] = table
+		}
+		for j := range cable.Sections {
+			cs := &cable.Sections[j]
+			if cs.R0 > 0 && cs.X0 > 0 {
 				continue
 			}
-			quantity := float64(q)
-
-			if props, ok := pracData[el]; ok && len(props) >= 3 {
-				omega := props[0]
-				tv := props[1]
-				tp := props[2]
-
-				woc_sum += quantity * omega
-				tvoc_num += quantity * omega * tv
-
-				if tp > max_t_plan {
-					max_t_plan = tp
-				}
+			value, ok := table[cs.S]
+			if !ok {
+				return catalog, fmt.Errorf("cable %s: section %g is not a standard %s section", cable.ID, cs.S, cable.Material)
+			}
+			if cs.R0 <= 0 {
+				cs.R0 = value.R0
+			}
+			if cs.X0 <= 0 {
+				cs.X0 = value.X0
 			}
 		}
 
-		// Розрахунки
-		woc := woc_sum
-		var tvoc float64
-		if woc > 0 {
-			tvoc = tvoc_num / woc
-		}
+		sections := cable.Sections
+		sort.Slice(sections, func(a, b int) bool { return sections[a].S < sections[b].S })
+	}
+	return catalog, nil
+}
 
-		// Коефіцієнт аварійного простою одноколової системи
-		kaoc := (woc * tvoc) / 8760
-		// Коефіцієнт планового простою одноколової системи
-		kpoc := (1.2 * max_t_plan) / 8760
-		// Частота відмов одночасно двох кіл двоколової системи
-		wdk := 2 * woc * (kaoc + kpoc)
-		// Частота відмов двоколової системи з урахуванням секційного вимикача
-		wdc := wdk + 0.02
-		// Коефіцієнт надійності
-		var koef float64
-		if wdc > 0 {
-			koef = woc / wdc
+// Метод, що шукає кабель у каталозі за маркою, або (якщо марка не задана) за індексом типу кабеля
+func (c cableCatalog) findCable(id string, typeIndex int) (catalogCable, error) {
+	for _, cable := range c.Cables {
+		if (id != "" && cable.ID == id) || (id == "" && cable.TypeIndex == typeIndex) {
+			return cable, nil
 		}
+	}
+	if id != "" {
+		return catalogCable{}, fmt.Errorf("cable %q not found in catalog", id)
+	}
+	return catalogCable{}, fmt.Errorf("no cable in catalog for type index %d", typeIndex)
+}
 
-		// Пункт 2
-		w := 0.01
-		tv := 0.045 // 45 * 10^-3
-		Pm := 5120.0 // 5.12 * 10^3
-		Tm := 6451.0
-		kp := 0.004 // 4 * 10^-3
+// Метод, що розраховує поправкові коефіцієнти для кабеля
+// laying - спосіб прокладання, t - температура середовища, °C, n - кількість кабелів у групі
+func (c cableCatalog) derating(cable catalogCable, laying string, t float64, n int) (cableDerating, error) {
+	method, ok := c.Laying[laying]
+	if !ok {
+		return cableDerating{}, fmt.Errorf("unknown laying method %q", laying)
+	}
+	ambient, ok := c.Ambient[cable.Insulation][method.Base]
+	if !ok {
+		return cableDerating{}, fmt.Errorf("no ambient temperature table for %s cables laid in %s", cable.Insulation, method.Base)
+	}
+	Kt, err := ambient.lookup(t)
+	if err != nil {
+		return cableDerating{}, fmt.Errorf("ambient temperature: %v", err)
+	}
+	grouping, ok := c.Grouping[method.Base]
+	if !ok {
+		return cableDerating{}, fmt.Errorf("no grouping table for %s", method.Base)
+	}
+	// Для груп, більших за наведені в таблиці, беремо коефіцієнт останнього рядка та попереджаємо користувача
+	var warning string
+	if last := len(grouping.Points) - 1; last >= 0 && float64(n) > grouping.Points[last] {
+		warning = fmt.Sprintf("коефіцієнт Kn для %d кабелів прийнято як для %g (найбільша група в таблиці)", n, grouping.Points[last])
+		n = int(grouping.Points[last])
+	}
+	Kn, err := grouping.lookup(float64(n))
+	if err != nil {
+		return cableDerating{}, fmt.Errorf("number of cables: %v", err)
+	}
+
+	return cableDerating{
+		Laying:  method.Name,
+		Kt:      round(Kt, 3),
+		Kn:      round(Kn, 3),
+		Klaying: method.Factor,
+		K:       round(Kt*Kn*method.Factor, 3),
+		Warning: warning,
+	}, nil
+}
+
+// Метод, що обирає спосіб прокладання за замовчуванням, який допускає кабель:
+// в землі, якщо для нього задані допустимі струми в землі, інакше (неізольовані проводи) - на повітрі
+func (c cableCatalog) defaultLaying(cable catalogCable) string {
+	for _, cs := range cable.Sections {
+		if cs.IGround > 0 {
+			return "ground"
+		}
+	}
+	return "air"
+}
+
+// Метод, що повертає перерізи кабеля з тривало допустимими струмами з урахуванням способу прокладання
+// та поправкових коефіцієнтів. Перерізи, для яких такий спосіб прокладання не допускається, пропускаються
+func (c cableCatalog) sectionsFor(cable catalogCable, laying string, d cableDerating) ([]cableSection, error) {
+	base := c.Laying[laying].Base
+	var sections []cableSection
+	for _, cs := range cable.Sections {
+		Iallow := cs.IGround
+		if base == "air" {
+			Iallow = cs.IAir
+		}
+		if Iallow <= 0 {
+			continue
+		}
+		sections = append(sections, cableSection{S: cs.S, R0: cs.R0, X0: cs.X0, Iallow: round(Iallow*d.K, 1)})
+	}
+	if len(sections) == 0 {
+		return nil, fmt.Errorf("cable %s cannot be laid this way (%s)", cable.ID, d.Laying)
+	}
+	return sections, nil
+}
+
+// API Handler для каталогу кабелів
+// Без параметрів повертає весь каталог; з параметром id - одну марку;
+// з параметрами id та s - параметри перерізу з урахуванням поправкових коефіцієнтів (laying, t, n)
+func prac4CablesHandler(w http.ResponseWriter, r *http.Request) {
+	catalog, err := getCableCatalog()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		writeJSON(w, http.StatusOK, catalog)
+		return
+	}
+	cable, err := catalog.findCable(id, -1)
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		return
+	}
+	if r.URL.Query().Get("s") == "" {
+		writeJSON(w, http.StatusOK, cable)
+		return
+	}
+
+	S, err1 := getFloat(r, "s")
+	laying := r.URL.Query().Get("laying")
+	if laying == "" {
+		laying = catalog.defaultLaying(cable)
+	}
+	t := 15.0
+	if r.URL.Query().Get("t") != "" {
+		var errT error
+		t, errT = getFloat(r, "t")
+		if errT != nil {
+			err1 = errT
+		}
+	}
+	n := 1
+	if nStr := r.URL.Query().Get("n"); nStr != "" {
+		var errN error
+		n, errN = strconv.Atoi(nStr)
+		if errN != nil {
+			err1 = errN
+		}
+	}
+	if err1 != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Bad values: check inputs"})
+		return
+	}
+
+	d, err := catalog.derating(cable, laying, t, n)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	sections, err := catalog.sectionsFor(cable, laying, d)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	for _, cs := range sections {
+		if cs.S == S {
+			writeJSON(w, http.StatusOK, map[string]interface{}{"cable": cable.ID, "section": cs, "derating": d})
+			return
+		}
+	}
+	writeJSON(w, http.StatusNotFound, map[string]string{"error": fmt.Sprintf("section %g not found for cable %s", S, cable.ID)})
+}
+
+// Метод, що розраховує втрату напруги в трифазній лінії, В
+// I - струм, А; L - довжина, км
+func voltageDropVolts(I, L, r0, x0, cosPhi float64) float64 {
+	sinPhi := math.Sqrt(1 - cosPhi*cosPhi)
+	return math.Sqrt(3) * I * L * (r0*cosPhi + x0*sinPhi)
+}
+
+// Метод, що розраховує втрату напруги в трифазній лінії, %
+// U - номінальна напруга, кВ
+func voltageDropPercent(I, L, r0, x0, cosPhi, U float64) float64 {
+	return voltageDropVolts(I, L, r0, x0, cosPhi) / (U * 1000) * 100
+}
+
+// Метод, що розраховує час найбільших втрат τ, год, за часом використання максимуму навантаження Tm
+func lossTime(Tm float64) float64 {
+	return math.Pow(0.124+Tm/10000, 2) * 8760
+}
+
+// Критерій вибору перерізу кабеля та найменший стандартний переріз, що його задовольняє
+type cableCriterion struct {
+	Name       string  `json:"name"`
+	Required   float64 `json:"required"` // необхідне значення (переріз, струм або втрата напруги)
+	Unit       string  `json:"unit"`
+	MinSection float64 `json:"min_section"`
+	Governing  bool    `json:"governing"`
+}
+
+// Результат вибору перерізу кабеля
+type cableSelection struct {
+	Section   cableSection     `json:"section"`
+	Governing string           `json:"governing"`
+	Criteria  []cableCriterion `json:"criteria"`
+}
+
+// Вхідні дані для вибору перерізу кабеля
+type cableSelectionInput struct {
+	Sek     float64 // економічний переріз, мм^2
+	Smin    float64 // мінімальний переріз за термічною стійкістю, мм^2
+	Imax    float64 // найбільший тривалий струм (післяаварійний режим), А
+	I       float64 // струм нормального режиму для перевірки втрати напруги, А
+	L       float64 // довжина кабеля, км
+	CosPhi  float64
+	U       float64 // номінальна напруга, кВ
+	DUAllow float64 // допустима втрата напруги, %
+}
+
+// Метод, що обирає найменший стандартний переріз, який одночасно задовольняє
+// економічну густину струму, термічну стійкість, тривало допустимий струм та втрату напруги
+// Переріз ніколи не заокруглюється в менший бік
+func selectCableSection(sections []cableSection, in cableSelectionInput) (cableSelection, error) {
+	criteria := []struct {
+		name     string
+		required float64
+		unit     string
+		ok       func(c cableSection) bool
+	}{
+		{"економічна густина струму", in.Sek, "мм²", func(c cableSection) bool { return c.S >= in.Sek }},
+		{"термічна стійкість", in.Smin, "мм²", func(c cableSection) bool { return c.S >= in.Smin }},
+		{"тривало допустимий струм", in.Imax, "А", func(c cableSection) bool { return c.Iallow >= in.Imax }},
+		{"втрата напруги", in.DUAllow, "%", func(c cableSection) bool {
+			return voltageDropPercent(in.I, in.L, c.R0, c.X0, in.CosPhi, in.U) <= in.DUAllow
+		}},
+	}
+
+	var selection cableSelection
+	chosen := -1
+	for _, cr := range criteria {
+		idx := -1
+		for i, c := range sections {
+			if cr.ok(c) {
+				idx = i
+				break
+			}
+		}
+		if idx == -1 {
+			return selection, fmt.Errorf("жоден стандартний переріз не задовольняє критерій \"%s\"", cr.name)
+		}
+		if idx > chosen {
+			chosen = idx
+		}
+		selection.Criteria = append(selection.Criteria, cableCriterion{
+			Name:       cr.name,
+			Required:   round(cr.required, 2),
+			Unit:       cr.unit,
+			MinSection: sections[idx].S,
+		})
+	}
+
+	selection.Section = sections[chosen]
+	// Визначальним є критерій, що вимагає найбільшого перерізу
+	for i := range selection.Criteria {
+		if selection.Criteria[i].MinSection == selection.Section.S {
+			selection.Criteria[i].Governing = true
+			if selection.Governing == "" {
+				selection.Governing = selection.Criteria[i].Name
+			}
+		}
+	}
+	return selection, nil
+}
+
+// Файл, у якому зберігаються сценарії мережі для розрахунку струмів КЗ
+const prac4ScenariosFile = "./instance/prac_4_scenarios.json"
+
+// Захищає файл сценаріїв від одночасного читання-зміни-запису кількома запитами
+var prac4ScenariosMu sync.Mutex
+
+// Сценарій мережі: дані, передані з підстанції, та параметри відхідної лінії 10 кВ
+type networkScenario struct {
+	Name     string    `json:"name"`
+	Rcn      float64   `json:"Rcn"`      // активний опір системи в нормальному режимі, Ом
+	Xcn      float64   `json:"Xcn"`      // реактивний опір системи в нормальному режимі, Ом
+	Rcmin    float64   `json:"Rcmin"`    // активний опір системи в мінімальному режимі, Ом
+	Xcmin    float64   `json:"Xcmin"`    // реактивний опір системи в мінімальному режимі, Ом
+	Uk_max   float64   `json:"Uk_max"`   // напруга КЗ трансформатора, %
+	Uvn      float64   `json:"Uvn"`      // номінальна напруга обмотки ВН, кВ
+	Unn      float64   `json:"Unn"`      // номінальна напруга обмотки НН, кВ
+	Snomt    float64   `json:"Snomt"`    // номінальна потужність трансформатора, МВ*А
+	R0       float64   `json:"R0"`       // питомий активний опір лінії, Ом/км
+	X0       float64   `json:"X0"`       // питомий реактивний опір лінії, Ом/км
+	Segments []float64 `json:"segments"` // довжини відрізків лінії, км
+
+	// Параметри для розрахунку однофазного КЗ (необов'язкові, за замовчуванням - типові значення)
+	Z0Z1       float64 `json:"z0_z1,omitempty"`      // відношення Z0/Z1 системи (за замовчуванням 1)
+	Connection string  `json:"connection,omitempty"` // схема з'єднання обмоток трансформатора (за замовчуванням YNd11)
+	RZero      float64 `json:"R_zero,omitempty"`     // питомий активний опір нульової послідовності лінії, Ом/км (за замовчуванням 3.5*R0)
+	XZero      float64 `json:"X_zero,omitempty"`     // питомий реактивний опір нульової послідовності лінії, Ом/км (за замовчуванням 3.5*X0)
+}
+
+// Метод, що перевіряє коректність сценарію мережі
+func (sc networkScenario) validate() error {
+	if sc.Uvn <= 0 || sc.Unn <= 0 || sc.Snomt <= 0 || sc.Uk_max <= 0 {
+		return fmt.Errorf("Uvn, Unn, Snomt та Uk_max мають бути більше 0")
+	}
+	if sc.Rcn < 0 || sc.Xcn < 0 || sc.Rcmin < 0 || sc.Xcmin < 0 || sc.R0 < 0 || sc.X0 < 0 {
+		return fmt.Errorf("опори не можуть бути від'ємними")
+	}
+	if len(sc.Segments) == 0 {
+		return fmt.Errorf("лінія має містити хоча б один відрізок")
+	}
+	for _, l := range sc.Segments {
+		if l < 0 {
+			return fmt.Errorf("довжина відрізка не може бути від'ємною")
+		}
+	}
+	if sc.Z0Z1 < 0 || sc.RZero < 0 || sc.XZero < 0 {
+		return fmt.Errorf("параметри нульової послідовності не можуть бути від'ємними")
+	}
+	if _, _, err := parseWindingConnection(sc.connection()); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Схема з'єднання обмоток трансформатора (типова для трансформаторів 110/10 кВ - YNd11)
+func (sc networkScenario) connection() string {
+	if strings.TrimSpace(sc.Connection) == "" {
+		return "YNd11"
+	}
+	return sc.Connection
+}
+
+// Загальна довжина лінії, км
+func (sc networkScenario) lineLength() float64 {
+	var total float64
+	for _, l := range sc.Segments {
+		total += l
+	}
+	return total
+}
+
+// Метод, що читає збережені сценарії мережі з файлу
+func getNetworkScenarios() ([]networkScenario, error) {
+	file, err := os.Open(prac4ScenariosFile)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var scenarios []networkScenario
+	if err := json.NewDecoder(file).Decode(&scenarios); err != nil {
+		return nil, err
+	}
+	return scenarios, nil
+}
+
+// Метод, що шукає сценарій мережі за назвою
+func getNetworkScenario(name string) (networkScenario, error) {
+	scenarios, err := getNetworkScenarios()
+	if err != nil {
+		return networkScenario{}, err
+	}
+	for _, sc := range scenarios {
+		if sc.Name == name {
+			return sc, nil
+		}
+	}
+	return networkScenario{}, fmt.Errorf("scenario %q not found", name)
+}
+
+// Метод, що зберігає (або оновлює) сценарій мережі у файлі
+// Сценарій "default" перезаписати не можна, щоб контрольний приклад завжди був доступний
+func saveNetworkScenario(sc networkScenario) error {
+	sc.Name = strings.TrimSpace(sc.Name)
+	if sc.Name == "" {
+		return fmt.Errorf("назва сценарію не може бути порожньою")
+	}
+	if sc.Name == "default" {
+		return fmt.Errorf("сценарій default не можна перезаписати")
+	}
+	if err := sc.validate(); err != nil {
+		return err
+	}
+
+	prac4ScenariosMu.Lock()
+	defer prac4ScenariosMu.Unlock()
+
+	scenarios, err := getNetworkScenarios()
+	if err != nil {
+		return err
+	}
+
+	replaced := false
+	for i := range scenarios {
+		if scenarios[i].Name == sc.Name {
+			scenarios[i] = sc
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		scenarios = append(scenarios, sc)
+	}
+	return writeJSONFile(prac4ScenariosFile, scenarios)
+}
+
+// Метод, що атомарно записує дані у JSON файл: спочатку у тимчасовий файл у тій самій теці,
+// а потім перейменовує його, щоб читачі ніколи не бачили обрізаний або частково записаний файл
+func writeJSONFile(path string, v interface{}) error {
+	file, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpName := file.Name()
+
+	encoder := json.NewEncoder(file)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		file.Close()
+		os.Remove(tmpName)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	// CreateTemp створює файл з правами 0600, тому зберігаємо права вихідного файла
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.Chmod(tmpName, mode); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	return nil
+}
+
+// Метод, що розбирає список довжин відрізків лінії, розділених ';' або пробілами
+func parseSegments(value string) ([]float64, error) {
+	fields := strings.FieldsFunc(value, func(c rune) bool {
+		return c == ';' || c == ' ' || c == '\t' || c == '\n' || c == '+'
+	})
+	var segments []float64
+	for _, f := range fields {
+		l, err := strconv.ParseFloat(strings.ReplaceAll(f, ",", "."), 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad segment length %q", f)
+		}
+		segments = append(segments, l)
+	}
+	return segments, nil
+}
+
+// Метод, що перетворює список відрізків у рядок для поля форми
+func formatSegments(segments []float64) string {
+	parts := make([]string, 0, len(segments))
+	for _, l := range segments {
+		parts = append(parts, strconv.FormatFloat(l, 'g', -1, 64))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Метод, що отримує сценарій мережі з форми
+func getNetworkScenarioFromForm(r *http.Request) (networkScenario, error) {
+	var sc networkScenario
+	var errs [10]error
+	sc.Name = strings.TrimSpace(r.FormValue("scenario_name"))
+	sc.Rcn, errs[0] = getFloat(r, "Rcn")
+	sc.Xcn, errs[1] = getFloat(r, "Xcn")
+	sc.Rcmin, errs[2] = getFloat(r, "Rcmin")
+	sc.Xcmin, errs[3] = getFloat(r, "Xcmin")
+	sc.Uk_max, errs[4] = getFloat(r, "Uk_max")
+	sc.Uvn, errs[5] = getFloat(r, "Uvn")
+	sc.Unn, errs[6] = getFloat(r, "Unn")
+	sc.Snomt, errs[7] = getFloat(r, "Snomt")
+	sc.R0, errs[8] = getFloat(r, "R0")
+	sc.X0, errs[9] = getFloat(r, "X0")
+	for _, err := range errs {
+		if err != nil {
+			return sc, err
+		}
+	}
+
+	segments, err := parseSegments(r.FormValue("segments"))
+	if err != nil {
+		return sc, err
+	}
+	sc.Segments = segments
+
+	// Параметри нульової послідовності можна не задавати, тоді використовуються типові значення
+	sc.Connection = strings.TrimSpace(r.FormValue("connection"))
+	optional := map[string]*float64{"z0_z1": &sc.Z0Z1, "R_zero": &sc.RZero, "X_zero": &sc.XZero}
+	for key, field := range optional {
+		if r.FormValue(key) == "" {
+			continue
+		}
+		v, err := getFloat(r, key)
+		if err != nil {
+			return sc, err
+		}
+		*field = v
+	}
+	return sc, sc.validate()
+}
+
+// Метод, що заносить параметри сценарію у значення за замовчуванням для форми
+func scenarioDefaults(defaultValues map[string]interface{}, sc networkScenario) {
+	defaultValues["scenario"] = sc.Name
+	defaultValues["Rcn"] = sc.Rcn
+	defaultValues["Xcn"] = sc.Xcn
+	defaultValues["Rcmin"] = sc.Rcmin
+	defaultValues["Xcmin"] = sc.Xcmin
+	defaultValues["Uk_max"] = sc.Uk_max
+	defaultValues["Uvn"] = sc.Uvn
+	defaultValues["Unn"] = sc.Unn
+	defaultValues["Snomt"] = sc.Snomt
+	defaultValues["R0"] = sc.R0
+	defaultValues["X0"] = sc.X0
+	defaultValues["segments"] = formatSegments(sc.Segments)
+	defaultValues["connection"] = sc.connection()
+	defaultValues["z0_z1"] = optionalValue(sc.Z0Z1)
+	defaultValues["R_zero"] = optionalValue(sc.RZero)
+	defaultValues["X_zero"] = optionalValue(sc.XZero)
+}
+
+// Допоміжна функція: незадане (нульове) необов'язкове значення показуємо у формі порожнім полем
+func optionalValue(v float64) interface{} {
+	if v == 0 {
+		return ""
+	}
+	return v
+}
+
+// Результати розрахунку струмів КЗ на шинах 10 кВ та на відхідній лінії
+type shortCircuitResult struct {
+	Xt_tr float64
+
+	Rsh, Xsh, Zsh          float64
+	Rshmin, Xshmin, Zshmin float64
+	Ish_3, Ish_2           float64
+	Ish_min_3, Ish_min_2   float64
+
+	kpr                          float64
+	Rshn, Xshn, Zshn             float64
+	Rshn_min, Xshn_min, Zshn_min float64
+	Ishn_3, Ishn_2               float64
+	Ishn_min_3, Ishn_min_2       float64
+
+	Il, Rl, Xl                float64
+	Ren, Xen, Zen             float64
+	Ren_min, Xen_min, Zen_min float64
+	Iln_3, Iln_2              float64
+	Iln_min_3, Iln_min_2      float64
+}
+
+// Метод, що розраховує струми КЗ для заданого сценарію мережі
+func calcShortCircuit(sc networkScenario) shortCircuitResult {
+	var res shortCircuitResult
+
+	// Розрахуємо реактивний опір силового трансформатора
+	res.Xt_tr = (sc.Uk_max * math.Pow(sc.Uvn, 2)) / (100 * sc.Snomt)
+
+	// Розрахуємо опори на шинах 10 кВ в нормальному та мінімальному режимах
+	res.Rsh = sc.Rcn
+	res.Xsh = sc.Xcn + res.Xt_tr
+	res.Zsh = math.Sqrt(math.Pow(res.Rsh, 2) + math.Pow(res.Xsh, 2))
+
+	res.Rshmin = sc.Rcmin
+	res.Xshmin = sc.Xcmin + res.Xt_tr
+	res.Zshmin = math.Sqrt(math.Pow(res.Rshmin, 2) + math.Pow(res.Xshmin, 2))
+
+	// Розраховуємо струми трифазного та двофазного КЗ на шинах 10 кВ
+	res.Ish_3 = (sc.Uvn * 1000) / (math.Sqrt(3) * res.Zsh)
+	res.Ish_2 = res.Ish_3 * math.Sqrt(3) / 2
+
+	res.Ish_min_3 = (sc.Uvn * 1000) / (math.Sqrt(3) * res.Zshmin)
+	res.Ish_min_2 = res.Ish_min_3 * math.Sqrt(3) / 2
+
+	// Розраховуємо коефіцієнт приведення
+	res.kpr = math.Pow(sc.Unn, 2) / math.Pow(sc.Uvn, 2)
+
+	// Розраховуємо опори на шинах 10 кВ в нормальному
+	// та мінімальному режимах і заносимо їх в карту вставок
+	res.Rshn = res.Rsh * res.kpr
+	res.Xshn = res.Xsh * res.kpr
+	res.Zshn = math.Sqrt(math.Pow(res.Rshn, 2) + math.Pow(res.Xshn, 2))
+
+	res.Rshn_min = res.Rshmin * res.kpr
+	res.Xshn_min = res.Xshmin * res.kpr
+	res.Zshn_min = math.Sqrt(math.Pow(res.Rshn_min, 2) + math.Pow(res.Xshn_min, 2))
+
+	// Розраховуємо дійсні струми трифазного та двофазного КЗ
+	res.Ishn_3 = (sc.Unn * 1000) / (math.Sqrt(3) * res.Zshn)
+	res.Ishn_2 = res.Ishn_3 * math.Sqrt(3) / 2
+
+	res.Ishn_min_3 = (sc.Unn * 1000) / (math.Sqrt(3) * res.Zshn_min)
+	res.Ishn_min_2 = res.Ishn_min_3 * math.Sqrt(3) / 2
+
+	// Розрахунок струмів короткого замикання відхідних ліній 10 кВ
+	// Знайдемо резистанси та реактанси відрізка з найбільшим опором
+	res.Il = sc.lineLength()
+	res.Rl = res.Il * sc.R0
+	res.Xl = res.Il * sc.X0
+
+	// Розрахуємо опори в нормальному та мінімальному режимах
+	res.Ren = res.Rl + res.Rshn
+	res.Xen = res.Xl + res.Xshn
+	res.Zen = math.Sqrt(math.Pow(res.Ren, 2) + math.Pow(res.Xen, 2))
+
+	res.Ren_min = res.Rl + res.Rshn_min
+	res.Xen_min = res.Xl + res.Xshn_min
+	res.Zen_min = math.Sqrt(math.Pow(res.Ren_min, 2) + math.Pow(res.Xen_min, 2))
+
+	// Розрахуємо струми трифазного і двофазного КЗ
+	res.Iln_3 = (sc.Unn * 1000) / (math.Sqrt(3) * res.Zen)
+	res.Iln_2 = res.Iln_3 * math.Sqrt(3) / 2
+
+	res.Iln_min_3 = (sc.Unn * 1000) / (math.Sqrt(3) * res.Zen_min)
+	res.Iln_min_2 = res.Iln_min_3 * math.Sqrt(3) / 2
+
+	return res
+}
+
+// Струми несиметричних КЗ на шинах 10 кВ та в кінці відхідної лінії для одного режиму системи
+type groundFaultResult struct {
+	Grounded bool           // чи є в мережі 10 кВ шлях для струмів нульової послідовності
+	Bus      busFaultResult // КЗ на шинах 10 кВ
+	LineEnd  busFaultResult // КЗ в кінці відхідної лінії
+}
+
+// Метод, що розраховує струми однофазного та двофазного на землю КЗ методом симетричних складових
+// Схема система - трансформатор - лінія будується зі сценарію для заданих опорів системи (Rc, Xc)
+func (sc networkScenario) groundFaults(Rc, Xc float64) (groundFaultResult, error) {
+	model := networkModel{
+		Buses: []networkBus{{ID: "ВН", Ukv: sc.Uvn}, {ID: "ш10", Ukv: sc.Unn}},
+		Branches: []networkBranch{
+			{Type: "source", From: "ВН", Rohm: Rc, Xohm: Xc, Z0Z1: sc.Z0Z1},
+			{Type: "transformer", From: "ВН", To: "ш10", SnomMVA: sc.Snomt, UkPct: sc.Uk_max, Connection: sc.connection()},
+		},
+	}
+	// Лінія нульової довжини (або без опору) не змінює струмів: КЗ в її кінці - те саме, що КЗ на шинах
+	hasLine := sc.lineLength() > 0 && (sc.R0 > 0 || sc.X0 > 0)
+	if hasLine {
+		model.Buses = append(model.Buses, networkBus{ID: "кінець лінії", Ukv: sc.Unn})
+		model.Branches = append(model.Branches, networkBranch{
+			Type: "line", From: "ш10", To: "кінець лінії",
+			R0: sc.R0, X0: sc.X0, RZero: sc.RZero, XZero: sc.XZero, LengthKm: sc.lineLength(),
+		})
+	}
+
+	faults, err := solveNetworkFaults(&model)
+	if err != nil {
+		return groundFaultResult{}, err
+	}
+	res := groundFaultResult{Bus: faults[1], LineEnd: faults[1]}
+	if hasLine {
+		res.LineEnd = faults[2]
+	}
+	res.Grounded = res.Bus.Z0ohm != nil
+	return res, nil
+}
+
+// Проміжний крок розрахунку для режиму "показати виведення"
+type calcStep struct {
+	Name    string  `json:"name"`
+	Value   float64 `json:"value"`
+	Unit    string  `json:"unit"`
+	Formula string  `json:"formula"`
+}
+
+// Метод, що повертає усі проміжні величини розрахунку струмів КЗ з одиницями та формулами
+func (res shortCircuitResult) steps(sc networkScenario) []calcStep {
+	return []calcStep{
+		{"Xт.вн", res.Xt_tr, "Ом", "Uк.max·Uвн² / (100·Sном.т)"},
+		{"Rш", res.Rsh, "Ом", "Rс.н"},
+		{"Xш", res.Xsh, "Ом", "Xс.н + Xт.вн"},
+		{"Zш", res.Zsh, "Ом", "√(Rш² + Xш²)"},
+		{"Rш.min", res.Rshmin, "Ом", "Rс.min"},
+		{"Xш.min", res.Xshmin, "Ом", "Xс.min + Xт.вн"},
+		{"Zш.min", res.Zshmin, "Ом", "√(Rш.min² + Xш.min²)"},
+		{"Iш(3)", res.Ish_3, "А", "Uвн·1000 / (√3·Zш)"},
+		{"Iш(2)", res.Ish_2, "А", "Iш(3)·√3/2"},
+		{"Iш.min(3)", res.Ish_min_3, "А", "Uвн·1000 / (√3·Zш.min)"},
+		{"Iш.min(2)", res.Ish_min_2, "А", "Iш.min(3)·√3/2"},
+		{"kпр", res.kpr, "", "Uнн² / Uвн²"},
+		{"Rш.н", res.Rshn, "Ом", "Rш·kпр"},
+		{"Xш.н", res.Xshn, "Ом", "Xш·kпр"},
+		{"Zш.н", res.Zshn, "Ом", "√(Rш.н² + Xш.н²)"},
+		{"Rш.н.min", res.Rshn_min, "Ом", "Rш.min·kпр"},
+		{"Xш.н.min", res.Xshn_min, "Ом", "Xш.min·kпр"},
+		{"Zш.н.min", res.Zshn_min, "Ом", "√(Rш.н.min² + Xш.н.min²)"},
+		{"Iш.н(3)", res.Ishn_3, "А", "Uнн·1000 / (√3·Zш.н)"},
+		{"Iш.н(2)", res.Ishn_2, "А", "Iш.н(3)·√3/2"},
+		{"Iш.н.min(3)", res.Ishn_min_3, "А", "Uнн·1000 / (√3·Zш.н.min)"},
+		{"Iш.н.min(2)", res.Ishn_min_2, "А", "Iш.н.min(3)·√3/2"},
+		{"lл", res.Il, "км", "Σ lі (" + formatSegments(sc.Segments) + ")"},
+		{"Rл", res.Rl, "Ом", "lл·R0"},
+		{"Xл", res.Xl, "Ом", "lл·X0"},
+		{"RΣн", res.Ren, "Ом", "Rл + Rш.н"},
+		{"XΣн", res.Xen, "Ом", "Xл + Xш.н"},
+		{"ZΣн", res.Zen, "Ом", "√(RΣн² + XΣн²)"},
+		{"RΣн.min", res.Ren_min, "Ом", "Rл + Rш.н.min"},
+		{"XΣн.min", res.Xen_min, "Ом", "Xл + Xш.н.min"},
+		{"ZΣн.min", res.Zen_min, "Ом", "√(RΣн.min² + XΣн.min²)"},
+		{"Iл.н(3)", res.Iln_3, "А", "Uнн·1000 / (√3·ZΣн)"},
+		{"Iл.н(2)", res.Iln_2, "А", "Iл.н(3)·√3/2"},
+		{"Iл.н.min(3)", res.Iln_min_3, "А", "Uнн·1000 / (√3·ZΣн.min)"},
+		{"Iл.н.min(2)", res.Iln_min_2, "А", "Iл.н.min(3)·√3/2"},
+	}
+}
+
+// Дані для перевірки на термічну та динамічну стійкість
+type withstandData struct {
+	// Коефіцієнт C (А*с^0.5/мм^2) для кожного типу кабеля (у тому ж порядку, що й у prac_4_cabels_data.json)
+	ThermalC []float64 `json:"thermal_c"`
+	// Матеріали шин: коефіцієнт C та допустима механічна напруга, МПа
+	BusbarMaterials map[string]struct {
+		Name       string  `json:"name"`
+		C          float64 `json:"c"`
+		SigmaAllow float64 `json:"sigma_allow"`
+	} `json:"busbar_materials"`
+}
+
+// Метод, що читає дані для перевірки на термічну та динамічну стійкість
+func getWithstandData() (withstandData, error) {
+	var data withstandData
+	file, err := os.Open("./instance/prac_4_withstand_data.json")
+	if err != nil {
+		return data, err
+	}
+	defer file.Close()
+
+	err = json.NewDecoder(file).Decode(&data)
+	return data, err
+}
+
+// Метод, що розраховує ударний струм КЗ
+// Стала часу загасання аперіодичної складової Ta = X / (ω * R), ударний коефіцієнт Ky = 1 + e^(-0.01/Ta)
+func calcPeakCurrent(I, X, R float64) (Ta, Ky, iy float64) {
+	if R > 0 {
+		Ta = X / (2 * math.Pi * 50 * R)
+		Ky = 1 + math.Exp(-0.01/Ta)
+	} else {
+		// Чисто індуктивне коло: аперіодична складова не загасає
+		Ta = math.Inf(1)
+		Ky = 2
+	}
+	iy = math.Sqrt(2) * Ky * I
+	return Ta, Ky, iy
+}
+
+// Результати перевірки шин на динамічну стійкість
+type busbarCheck struct {
+	F          float64 // найбільше зусилля на шину середньої фази, Н
+	M          float64 // згинальний момент, Н*м
+	W          float64 // момент опору перерізу шини, см^3
+	Sigma      float64 // розрахункова механічна напруга, МПа
+	SigmaAllow float64 // допустима механічна напруга, МПа
+	Ok         bool
+}
+
+// Метод, що перевіряє шини на динамічну стійкість при трифазному КЗ
+// iy - ударний струм, А; l - проліт між ізоляторами, м; a - відстань між фазами, м;
+// b, h - розміри шини, мм (h - розмір у напрямку дії зусилля)
+func calcBusbarCheck(iy, l, a, b, h, sigmaAllow float64) busbarCheck {
+	// Зусилля між фазами при трифазному КЗ
+	F := math.Sqrt(3) * 1e-7 * (l / a) * math.Pow(iy, 2)
+	// Згинальний момент для багатопролітної шини
+	M := F * l / 10
+	// Момент опору перерізу шини, м^3
+	W := (b / 1000) * math.Pow(h/1000, 2) / 6
+	sigma := M / W / 1e6
+
+	return busbarCheck{
+		F:          F,
+		M:          M,
+		W:          W * 1e6,
+		Sigma:      sigma,
+		SigmaAllow: sigmaAllow,
+		Ok:         sigma <= sigmaAllow,
+	}
+}
+
+// Силовий трансформатор з каталогу
+type transformer struct {
+	ID    string  `json:"id"`
+	Skva  float64 `json:"s_kva"`   // номінальна потужність, кВ*А
+	Uhv   float64 `json:"u_hv_kv"` // номінальна напруга обмотки ВН, кВ
+	Ulv   float64 `json:"u_lv_kv"` // номінальна напруга обмотки НН, кВ
+	UkPct float64 `json:"uk_pct"`  // напруга КЗ, %
+	PkKW  float64 `json:"pk_kw"`   // втрати КЗ, кВт
+	P0KW  float64 `json:"p0_kw"`   // втрати холостого ходу, кВт
+	I0Pct float64 `json:"i0_pct"`  // струм холостого ходу, %
+}
+
+// Метод, що читає каталог трансформаторів з файлу
+func getTransformers() ([]transformer, error) {
+	file, err := os.Open("./instance/prac_4_transformers.json")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var transformers []transformer
+	if err := json.NewDecoder(file).Decode(&transformers); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(transformers, func(i, j int) bool { return transformers[i].Skva < transformers[j].Skva })
+	return transformers, nil
+}
+
+// Вхідні дані для вибору трансформаторів
+type transformerSelectionInput struct {
+	Sm        float64 // розрахункове навантаження, кВ*А
+	N         int     // кількість трансформаторів (0 - порівняти варіанти з одним та двома трансформаторами)
+	Category  int     // категорія надійності споживачів (1, 2 або 3)
+	Uhv, Ulv  float64 // напруги обмоток (0 - будь-які)
+	Kz1       float64 // допустимий коефіцієнт завантаження однотрансформаторної підстанції
+	Kz2       float64 // допустимий коефіцієнт завантаження багатотрансформаторної підстанції
+	KOverload float64 // допустимий коефіцієнт аварійного перевантаження
+}
+
+// Варіант встановлення трансформаторів
+type transformerOption struct {
+	Transformer transformer `json:"transformer"`
+	N           int         `json:"n"`
+	Kz          float64     `json:"kz"`           // коефіцієнт завантаження в нормальному режимі
+	KzEmergency float64     `json:"kz_emergency"` // коефіцієнт завантаження при вимкненні одного трансформатора
+	LossesKW    float64     `json:"losses_kw"`    // сумарні втрати активної потужності, кВт
+	Ok          bool        `json:"ok"`
+	Reserve     bool        `json:"reserve"` // чи забезпечує варіант резервування, потрібне за категорією надійності
+	Recommended bool        `json:"recommended"`
+	Reason      string      `json:"reason,omitempty"`
+}
+
+// Найбільший допустимий коефіцієнт аварійного перевантаження масляних трансформаторів
+const maxTransformerOverload = 2.0
+
+// Метод, що обирає кількість та потужність трансформаторів за розрахунковим навантаженням
+// Якщо кількість не задана, порівнює одно- та двотрансформаторні варіанти
+// Повертає рекомендований варіант, усі перевірені варіанти та попередження
+func selectTransformers(catalog []transformer, in transformerSelectionInput) (transformerOption, []transformerOption, []string, error) {
+	counts := []int{1, 2}
+	if in.N > 0 {
+		counts = []int{in.N}
+	}
+
+	var checked []transformerOption
+	for _, n := range counts {
+		kzAllow := in.Kz2
+		if n == 1 {
+			kzAllow = in.Kz1
+		}
+		for _, t := range catalog {
+			if (in.Uhv > 0 && t.Uhv != in.Uhv) || (in.Ulv > 0 && t.Ulv != in.Ulv) {
+				continue
+			}
+
+			// Споживачі I та II категорій потребують резервування живлення,
+			// яке однотрансформаторна підстанція не забезпечує
+			option := transformerOption{Transformer: t, N: n, Ok: true, Reserve: n > 1 || in.Category == 3}
+			option.Kz = in.Sm / (float64(n) * t.Skva)
+			option.LossesKW = float64(n) * (t.P0KW + t.PkKW*math.Pow(option.Kz, 2))
+
+			var reasons []string
+			if option.Kz > kzAllow {
+				option.Ok = false
+				reasons = append(reasons, fmt.Sprintf("Kз=%.2f > %.2f", option.Kz, kzAllow))
+			}
+			if n > 1 {
+				// Аварійний режим: один з трансформаторів вимкнено
+				option.KzEmergency = in.Sm / (float64(n-1) * t.Skva)
+				if option.KzEmergency > in.KOverload {
+					option.Ok = false
+					reasons = append(reasons, fmt.Sprintf("Kз.ав=%.2f > %.2f", option.KzEmergency, in.KOverload))
+				}
+			}
+			if !option.Reserve {
+				reasons = append(reasons, "немає резерву для споживачів I/II категорії")
+			}
+			option.Reason = strings.Join(reasons, "; ")
+
+			option.Kz = round(option.Kz, 3)
+			option.KzEmergency = round(option.KzEmergency, 3)
+			option.LossesKW = round(option.LossesKW, 2)
+			checked = append(checked, option)
+		}
+	}
+
+	if len(checked) == 0 {
+		return transformerOption{}, nil, nil, fmt.Errorf("у каталозі немає трансформаторів із заданими напругами")
+	}
+
+	// Серед варіантів, що проходять за завантаженням, перевагу мають варіанти з потрібним резервуванням,
+	// далі - з меншою встановленою потужністю, меншими втратами та меншою кількістю трансформаторів
+	better := func(a, b transformerOption) bool {
+		if a.Reserve != b.Reserve {
+			return a.Reserve
+		}
+		sa, sb := float64(a.N)*a.Transformer.Skva, float64(b.N)*b.Transformer.Skva
+		if sa != sb {
+			return sa < sb
+		}
+		if a.LossesKW != b.LossesKW {
+			return a.LossesKW < b.LossesKW
+		}
+		return a.N < b.N
+	}
+	recommended := -1
+	for i, option := range checked {
+		if option.Ok && (recommended == -1 || better(option, checked[recommended])) {
+			recommended = i
+		}
+	}
+	if recommended == -1 {
+		return transformerOption{}, checked, nil, fmt.Errorf("жоден трансформатор каталогу не задовольняє умови завантаження")
+	}
+	checked[recommended].Recommended = true
+
+	var warnings []string
+	if !checked[recommended].Reserve {
+		warnings = append(warnings, fmt.Sprintf("для споживачів %s категорії однотрансформаторна підстанція не забезпечує резервування живлення",
+			map[int]string{1: "I", 2: "II"}[in.Category]))
+	}
+	return checked[recommended], checked, warnings, nil
+}
+
+// Шлях, що обробляє вибір кількості та потужності трансформаторів
+func prac4Transformers(w http.ResponseWriter, r *http.Request) {
+	catalog, err := getTransformers()
+	if err != nil {
+		http.Error(w, "Failed to load transformers: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	defaultValues := map[string]interface{}{
+		"Sm":         1300.0,
+		"category":   "2",
+		"n":          "0",
+		"u_hv":       "10",
+		"u_lv":       "0.4",
+		"kz1":        0.9,
+		"kz2":        0.7,
+		"k_overload": 1.4,
+		"catalog":    catalog,
+	}
+	data := PageData{IsIndex: false, DefaultValues: defaultValues}
+
+	if r.Method == http.MethodPost {
+		Sm, err1 := getFloat(r, "Sm")
+		category, err2 := strconv.Atoi(r.FormValue("category"))
+		n, err3 := strconv.Atoi(r.FormValue("n"))
+		kz1, err4 := getFloat(r, "kz1")
+		kz2, err5 := getFloat(r, "kz2")
+		kOverload, err6 := getFloat(r, "k_overload")
+
+		// Напруги обмоток можуть бути не задані (будь-які)
+		var Uhv, Ulv float64
+		var err7, err8 error
+		if r.FormValue("u_hv") != "" {
+			Uhv, err7 = getFloat(r, "u_hv")
+		}
+		if r.FormValue("u_lv") != "" {
+			Ulv, err8 = getFloat(r, "u_lv")
+		}
+
+		defaultValues["Sm"] = Sm
+		defaultValues["category"] = r.FormValue("category")
+		defaultValues["n"] = r.FormValue("n")
+		defaultValues["u_hv"] = r.FormValue("u_hv")
+		defaultValues["u_lv"] = r.FormValue("u_lv")
+		defaultValues["kz1"] = kz1
+		defaultValues["kz2"] = kz2
+		defaultValues["k_overload"] = kOverload
+
+		if err1 != nil || err2 != nil || err3 != nil || err4 != nil || err5 != nil || err6 != nil || err7 != nil || err8 != nil ||
+			Sm <= 0 || n < 0 || category < 1 || category > 3 || Uhv < 0 || Ulv < 0 ||
+			// Коефіцієнти завантаження в нормальному режимі не перевищують 1,
+			// а аварійне перевантаження лежить між номінальним завантаженням та найбільшим допустимим
+			kz1 <= 0 || kz1 > 1 || kz2 <= 0 || kz2 > 1 || kOverload < 1 || kOverload > maxTransformerOverload {
+			data.Error = "Bad values: check inputs"
+			respond(w, r, "prac_4_transformers", data, "templates/prac_4_transformers.html")
+			return
+		}
+
+		recommended, checked, warnings, err := selectTransformers(catalog, transformerSelectionInput{
+			Sm:        Sm,
+			N:         n,
+			Category:  category,
+			Uhv:       Uhv,
+			Ulv:       Ulv,
+			Kz1:       kz1,
+			Kz2:       kz2,
+			KOverload: kOverload,
+		})
+		data.Results = map[string]interface{}{"checked": checked}
+		if err != nil {
+			data.Error = err.Error()
+			respond(w, r, "prac_4_transformers", data, "templates/prac_4_transformers.html")
+			return
+		}
+		data.Results["recommended"] = recommended
+		data.Results["warnings"] = warnings
+	}
+
+	respond(w, r, "prac_4_transformers", data, "templates/prac_4_transformers.html")
+}
+
+// Вхідні дані для розрахунку уставок релейного захисту відхідної лінії 10 кВ
+type protectionInput struct {
+	Iload  float64 // максимальний робочий струм лінії, А
+	Kn     float64 // коефіцієнт надійності МСЗ
+	Ksz    float64 // коефіцієнт самозапуску
+	Kv     float64 // коефіцієнт повернення реле
+	KnSo   float64 // коефіцієнт надійності струмової відсічки
+	Kct    float64 // коефіцієнт трансформації трансформаторів струму
+	Ksch   float64 // коефіцієнт схеми з'єднання трансформаторів струму
+	Tdown  float64 // час спрацювання захисту наступної (нижчої) ступені, с
+	Dt     float64 // ступінь селективності, с
+	KchMtz float64 // мінімально допустимий коефіцієнт чутливості МСЗ
+	KchSo  float64 // мінімально допустимий коефіцієнт чутливості відсічки
+}
+
+// Уставки та перевірка одного ступеня захисту
+type protectionStage struct {
+	Name      string  `json:"name"`
+	Isz       float64 `json:"isz"`       // струм спрацювання захисту, А
+	Isr       float64 `json:"isr"`       // струм спрацювання реле, А
+	Ikz       float64 `json:"ikz"`       // струм КЗ, за яким перевіряється чутливість, А
+	Kch       float64 `json:"kch"`       // коефіцієнт чутливості
+	KchAllow  float64 `json:"kch_allow"` // мінімально допустимий коефіцієнт чутливості
+	Time      float64 `json:"time"`      // час спрацювання, с
+	Sensitive bool    `json:"sensitive"`
+}
+
+// Результати розрахунку релейного захисту
+type protectionResult struct {
+	Overcurrent   protectionStage `json:"overcurrent"`
+	Instantaneous protectionStage `json:"instantaneous"`
+	TupStream     float64         `json:"t_upstream"` // час МСЗ вводу 10 кВ, с
+	Warnings      []string        `json:"warnings"`
+}
+
+// Метод, що розраховує уставки МСЗ та струмової відсічки відхідної лінії 10 кВ
+// за струмами КЗ максимального та мінімального режимів
+func calcProtection(sc shortCircuitResult, in protectionInput) protectionResult {
+	var res protectionResult
+
+	// Максимальний струмовий захист: відбудова від максимального робочого струму з урахуванням самозапуску
+	mtz := protectionStage{Name: "МСЗ", KchAllow: in.KchMtz}
+	mtz.Isz = in.Kn * in.Ksz / in.Kv * in.Iload
+	mtz.Isr = mtz.Isz * in.Ksch / in.Kct
+	// Чутливість перевіряється за двофазним КЗ в кінці лінії в мінімальному режимі
+	mtz.Ikz = sc.Iln_min_2
+	mtz.Kch = mtz.Ikz / mtz.Isz
+	mtz.Sensitive = mtz.Kch >= in.KchMtz
+	mtz.Time = in.Tdown + in.Dt
+
+	// Струмова відсічка: відбудова від трифазного КЗ в кінці лінії в максимальному режимі
+	so := protectionStage{Name: "Струмова відсічка", KchAllow: in.KchSo}
+	so.Isz = in.KnSo * sc.Iln_3
+	so.Isr = so.Isz * in.Ksch / in.Kct
+	// Чутливість перевіряється за двофазним КЗ на початку лінії (шини 10 кВ) в мінімальному режимі
+	so.Ikz = sc.Ishn_min_2
+	so.Kch = so.Ikz / so.Isz
+	so.Sensitive = so.Kch >= in.KchSo
+	so.Time = 0
+
+	// Захист вводу 10 кВ має бути узгоджений з МСЗ лінії
+	res.TupStream = mtz.Time + in.Dt
+
+	if !mtz.Sensitive {
+		res.Warnings = append(res.Warnings, fmt.Sprintf("МСЗ нечутливий: Kч=%.2f < %.2f, потрібне резервування або захист з пуском за напругою", mtz.Kch, in.KchMtz))
+	}
+	if !so.Sensitive {
+		res.Warnings = append(res.Warnings, fmt.Sprintf("Струмова відсічка нечутлива: Kч=%.2f < %.2f, відсічку на лінії встановлювати недоцільно", so.Kch, in.KchSo))
+	}
+	if so.Isz <= mtz.Isz {
+		res.Warnings = append(res.Warnings, "Струм спрацювання відсічки не перевищує струм спрацювання МСЗ")
+	}
+
+	for _, stage := range []*protectionStage{&mtz, &so} {
+		stage.Isz = round(stage.Isz, 1)
+		stage.Isr = round(stage.Isr, 2)
+		stage.Ikz = round(stage.Ikz, 1)
+		stage.Kch = round(stage.Kch, 2)
+		stage.Time = round(stage.Time, 2)
+	}
+	res.Overcurrent = mtz
+	res.Instantaneous = so
+	res.TupStream = round(res.TupStream, 2)
+	return res
+}
+
+// Шлях, що обробляє розрахунок уставок релейного захисту відхідних ліній 10 кВ
+func prac4Protection(w http.ResponseWriter, r *http.Request) {
+	defaultValues := map[string]interface{}{
+		"I_load":  150.0,
+		"kn":      1.2,
+		"ksz":     1.5,
+		"kv":      0.85,
+		"kn_so":   1.3,
+		"kct":     40.0,
+		"ksch":    1.0,
+		"t_down":  0.5,
+		"dt":      0.4,
+		"kch_mtz": 1.5,
+		"kch_so":  1.2,
+	}
+	data := PageData{IsIndex: false, DefaultValues: defaultValues}
+
+	scenarios, err := getNetworkScenarios()
+	if err != nil {
+		http.Error(w, "Failed to load scenarios: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var scenarioNames []string
+	for _, sc := range scenarios {
+		scenarioNames = append(scenarioNames, sc.Name)
+	}
+	defaultValues["scenarios"] = scenarioNames
+
+	// Сценарій обирається з параметра запиту або поля форми
+	scenarioName := r.FormValue("scenario")
+	if scenarioName == "" {
+		scenarioName = "default"
+	}
+	defaultValues["scenario"] = scenarioName
+
+	if r.Method == http.MethodPost {
+		var in protectionInput
+		var errs [11]error
+		in.Iload, errs[0] = getFloat(r, "I_load")
+		in.Kn, errs[1] = getFloat(r, "kn")
+		in.Ksz, errs[2] = getFloat(r, "ksz")
+		in.Kv, errs[3] = getFloat(r, "kv")
+		in.KnSo, errs[4] = getFloat(r, "kn_so")
+		in.Kct, errs[5] = getFloat(r, "kct")
+		in.Ksch, errs[6] = getFloat(r, "ksch")
+		in.Tdown, errs[7] = getFloat(r, "t_down")
+		in.Dt, errs[8] = getFloat(r, "dt")
+		in.KchMtz, errs[9] = getFloat(r, "kch_mtz")
+		in.KchSo, errs[10] = getFloat(r, "kch_so")
+
+		defaultValues["I_load"] = in.Iload
+		defaultValues["kn"] = in.Kn
+		defaultValues["ksz"] = in.Ksz
+		defaultValues["kv"] = in.Kv
+		defaultValues["kn_so"] = in.KnSo
+		defaultValues["kct"] = in.Kct
+		defaultValues["ksch"] = in.Ksch
+		defaultValues["t_down"] = in.Tdown
+		defaultValues["dt"] = in.Dt
+		defaultValues["kch_mtz"] = in.KchMtz
+		defaultValues["kch_so"] = in.KchSo
+
+		for _, err := range errs {
+			if err != nil {
+				data.Error = "Bad values: check inputs"
+				respond(w, r, "prac_4_protection", data, "templates/prac_4_protection.html")
+				return
+			}
+		}
+		if in.Iload <= 0 || in.Kn <= 0 || in.Ksz <= 0 || in.Kv <= 0 || in.Kv > 1 || in.KnSo <= 0 ||
+			in.Kct <= 0 || in.Ksch <= 0 || in.Tdown < 0 || in.Dt < 0 || in.KchMtz <= 0 || in.KchSo <= 0 {
+			data.Error = "Bad values: check inputs"
+			respond(w, r, "prac_4_protection", data, "templates/prac_4_protection.html")
+			return
+		}
+
+		scenario, err := getNetworkScenario(scenarioName)
+		if err != nil {
+			data.Error = "Scenario data error: " + err.Error()
+			respond(w, r, "prac_4_protection", data, "templates/prac_4_protection.html")
+			return
+		}
+		sc := calcShortCircuit(scenario)
+
+		data.Results = map[string]interface{}{
+			"protection": calcProtection(sc, in),
+			// Струми КЗ, за якими розраховано уставки
+			"Ishn_3":     round(sc.Ishn_3, 1),
+			"Ishn_min_2": round(sc.Ishn_min_2, 1),
+			"Iln_3":      round(sc.Iln_3, 1),
+			"Iln_min_2":  round(sc.Iln_min_2, 1),
+		}
+	}
+
+	respond(w, r, "prac_4_protection", data, "templates/prac_4_protection.html")
+}
+
+// Шлях, що обробляє четверту практичну роботу
+func prac4Task1(w http.ResponseWriter, r *http.Request) {
+	// Значення за замовчуванням
+	defaultValues := map[string]interface{}{
+		"Ik": 2500.0,
+		"tf": 2.5,
+		"Sm": 1300.0,
+		"Tm": 4000.0,
+		"Sk": 200.0,
+		"cabel": "",
+		"jek_interpolate": false,
+		"show_derivation": false,
+		// Трансформатор для розрахунку початкового струму КЗ (пункт 2)
+		"t_uk":   10.5,
+		"t_snom": 6.3,
+		// Параметри кабеля для перевірки втрати напруги
+		"L":        0.5,
+		"cos_phi":  0.9,
+		"dU_allow": 5.0,
+		// Умови прокладання кабеля (порожній спосіб прокладання - обрати за типом кабеля)
+		"laying":    "",
+		"t_ambient": 15.0,
+		"n_cables":  1,
+		// Параметри шин 10 кВ для перевірки на динамічну стійкість
+		"bus_material": "Al",
+		"bus_l":        1.0,
+		"bus_a":        0.25,
+		"bus_b":        6.0,
+		"bus_h":        60.0,
+	}
+	data := PageData{
+		IsIndex:       false,
+		DefaultValues: defaultValues,
+	}
+
+	// Завантажуємо перелік сценаріїв мережі та обраний сценарій (за замовчуванням - контрольний приклад)
+	scenarios, err := getNetworkScenarios()
+	if err != nil {
+		data.Error = "Scenario data error: " + err.Error()
+		respond(w, r, "prac_4_task_1", data, "templates/prac_4_task_1.html")
+		return
+	}
+	var scenarioNames []string
+	for _, sc := range scenarios {
+		scenarioNames = append(scenarioNames, sc.Name)
+	}
+	defaultValues["scenarios"] = scenarioNames
+
+	scenarioName := r.URL.Query().Get("scenario")
+	if scenarioName == "" {
+		scenarioName = "default"
+	}
+	scenario, err := getNetworkScenario(scenarioName)
+	if err != nil {
+		data.Error = "Scenario data error: " + err.Error()
+		scenario, _ = getNetworkScenario("default")
+	}
+	scenarioDefaults(defaultValues, scenario)
+
+	// Каталог трансформаторів для швидкого заповнення параметрів трансформатора
+	if transformers, err := getTransformers(); err == nil {
+		defaultValues["transformers"] = transformers
+	}
+
+	// Способи прокладання кабеля для списку вибору беремо з каталогу кабелів
+	if catalog, err := getCableCatalog(); err == nil {
+		defaultValues["layings"] = catalog.Laying
+	}
+
+	// Режим пошуку економічної густини струму за замовчуванням задається у таблиці
+	if table, err := getJekTable(); err == nil {
+		defaultValues["jek_interpolate"] = table.Interpolate
+	}
+
+	if r.Method == http.MethodPost {
+		cabelStr := r.FormValue("cabel")
+		cabel, errC := strconv.Atoi(cabelStr)
+		Ik, err1 := getFloat(r, "Ik")
+		tf, err2 := getFloat(r, "tf")
+		Sm, err3 := getFloat(r, "Sm")
+		Tm, err4 := getFloat(r, "Tm")
+		Sk, err5 := getFloat(r, "Sk")
+		busMaterial := r.FormValue("bus_material")
+		bus_l, err6 := getFloat(r, "bus_l")
+		bus_a, err7 := getFloat(r, "bus_a")
+		bus_b, err8 := getFloat(r, "bus_b")
+		bus_h, err9 := getFloat(r, "bus_h")
+		L, err10 := getFloat(r, "L")
+		cosPhi, err11 := getFloat(r, "cos_phi")
+		dUAllow, err12 := getFloat(r, "dU_allow")
+		laying := r.FormValue("laying")
+		tAmbient, err13 := getFloat(r, "t_ambient")
+		// Кількість кабелів у групі - ціле число, тому дробові значення відхиляємо, а не відкидаємо дробову частину
+		nCables, err14 := strconv.Atoi(strings.TrimSpace(r.FormValue("n_cables")))
+		jekInterpolate := r.FormValue("jek_interpolate") != ""
+		showDerivation := r.FormValue("show_derivation") != ""
+		t_uk, err15 := getFloat(r, "t_uk")
+		t_snom, err16 := getFloat(r, "t_snom")
+
+		// Оновлюємо значення за замовчуванням на введені користувачем
+		defaultValues["Ik"] = Ik
+		defaultValues["tf"] = tf
+		defaultValues["Sm"] = Sm
+		defaultValues["Tm"] = Tm
+		defaultValues["Sk"] = Sk
+		defaultValues["cabel"] = cabelStr
+		defaultValues["bus_material"] = busMaterial
+		defaultValues["bus_l"] = bus_l
+		defaultValues["bus_a"] = bus_a
+		defaultValues["bus_b"] = bus_b
+		defaultValues["bus_h"] = bus_h
+		defaultValues["L"] = L
+		defaultValues["cos_phi"] = cosPhi
+		defaultValues["dU_allow"] = dUAllow
+		defaultValues["laying"] = laying
+		defaultValues["t_ambient"] = tAmbient
+		defaultValues["n_cables"] = nCables
+		defaultValues["jek_interpolate"] = jekInterpolate
+		defaultValues["show_derivation"] = showDerivation
+		defaultValues["t_uk"] = t_uk
+		defaultValues["t_snom"] = t_snom
+
+		if errC != nil || err1 != nil || err2 != nil || err3 != nil || err4 != nil || err5 != nil ||
+			err6 != nil || err7 != nil || err8 != nil || err9 != nil || bus_l <= 0 || bus_a <= 0 || bus_b <= 0 || bus_h <= 0 ||
+			err10 != nil || err11 != nil || err12 != nil || L < 0 || cosPhi <= 0 || cosPhi > 1 || dUAllow <= 0 ||
+			err13 != nil || err14 != nil || nCables < 1 || err15 != nil || err16 != nil || t_uk <= 0 || t_snom <= 0 {
+			data.Error = "Bad values: check inputs"
+			respond(w, r, "prac_4_task_1", data, "templates/prac_4_task_1.html")
+			return
+		}
+
+		// Отримуємо параметри мережі, введені користувачем
+		scenario, err := getNetworkScenarioFromForm(r)
+		if err != nil {
+			data.Error = "Bad network values: " + err.Error()
+			respond(w, r, "prac_4_task_1", data, "templates/prac_4_task_1.html")
+			return
+		}
+		scenarioDefaults(defaultValues, scenario)
+		// Форма містить усі параметри мережі, тому помилка завантаження сценарію з адреси вже не актуальна
+		data.Error = ""
+
+		// Якщо користувач попросив, зберігаємо мережу як новий сценарій
+		if r.FormValue("action") == "save" {
+			if err := saveNetworkScenario(scenario); err != nil {
+				data.Error = "Scenario save error: " + err.Error()
+				respond(w, r, "prac_4_task_1", data, "templates/prac_4_task_1.html")
+				return
+			}
+			if !containsString(scenarioNames, scenario.Name) {
+				defaultValues["scenarios"] = append(scenarioNames, scenario.Name)
+			}
+		}
+
+		// 1
+		// Розрахунковий струм для нормального і післяаварійного режимів
+		Im := (Sm / 2) / (math.Sqrt(3) * 10)
+		Im_pa := 2 * Im
+
+		// Отримуємо економічну густину струму
+		jek, errJ := getJek(cabel, Tm, jekInterpolate)
+		if errJ != nil {
+			data.Error = "Cable data error: " + errJ.Error()
+			// Для API повертаємо також структуровану інформацію про підтримуваний діапазон Tm
+			var rangeErr *jekRangeError
+			if errors.As(errJ, &rangeErr) {
+				data.Results = map[string]interface{}{"jek_error": rangeErr}
+			}
+			respond(w, r, "prac_4_task_1", data, "templates/prac_4_task_1.html")
+			return
+		}
+
+		// Отримуємо дані для перевірки на термічну та динамічну стійкість
+		withstand, errW := getWithstandData()
+		if errW != nil {
+			data.Error = "Withstand data error: " + errW.Error()
+			respond(w, r, "prac_4_task_1", data, "templates/prac_4_task_1.html")
+			return
+		}
+		if cabel < 0 || cabel >= len(withstand.ThermalC) {
+			data.Error = fmt.Sprintf("Withstand data error: C not found for index %d", cabel)
+			respond(w, r, "prac_4_task_1", data, "templates/prac_4_task_1.html")
+			return
+		}
+		material, ok := withstand.BusbarMaterials[busMaterial]
+		if !ok {
+			data.Error = "Bad values: unknown busbar material"
+			respond(w, r, "prac_4_task_1", data, "templates/prac_4_task_1.html")
+			return
+		}
+
+		// Рахуємо економічний переріз
+		sek := Im / jek
+		// Шукаємо мінімальний переріз за термічною стійкістю (C залежить від матеріалу та ізоляції)
+		C := withstand.ThermalC[cabel]
+		s_min := (Ik * math.Sqrt(tf)) / C
+
+		// Шукаємо кабель обраного типу в каталозі та визначаємо поправкові коефіцієнти
+		// до тривало допустимого струму з урахуванням умов прокладання
+		catalog, errS := getCableCatalog()
+		if errS != nil {
+			data.Error = "Cable data error: " + errS.Error()
+			respond(w, r, "prac_4_task_1", data, "templates/prac_4_task_1.html")
+			return
+		}
+		cable, errS := catalog.findCable("", cabel)
+		if errS != nil {
+			data.Error = "Cable data error: " + errS.Error()
+			respond(w, r, "prac_4_task_1", data, "templates/prac_4_task_1.html")
+			return
+		}
+		if laying == "" {
+			laying = catalog.defaultLaying(cable)
+		}
+		derating, errS := catalog.derating(cable, laying, tAmbient, nCables)
+		if errS != nil {
+			data.Error = "Cable derating error: " + errS.Error()
+			respond(w, r, "prac_4_task_1", data, "templates/prac_4_task_1.html")
+			return
+		}
+		sections, errS := catalog.sectionsFor(cable, laying, derating)
+		if errS != nil {
+			data.Error = "Cable data error: " + errS.Error()
+			respond(w, r, "prac_4_task_1", data, "templates/prac_4_task_1.html")
+			return
+		}
+
+		// Обираємо найменший стандартний переріз, що задовольняє усі критерії одночасно
+		selection, errS := selectCableSection(sections, cableSelectionInput{
+			Sek:     sek,
+			Smin:    s_min,
+			Imax:    Im_pa,
+			I:       Im,
+			L:       L,
+			CosPhi:  cosPhi,
+			U:       10,
+			DUAllow: dUAllow,
+		})
+		if errS != nil {
+			data.Error = "Cable selection error: " + errS.Error()
+			respond(w, r, "prac_4_task_1", data, "templates/prac_4_task_1.html")
+			return
+		}
+		s := selection.Section.S
+
+		// Втрата напруги в обраному кабелі для нормального та післяаварійного режимів
+		r0, x0 := selection.Section.R0, selection.Section.X0
+		dU := voltageDropVolts(Im, L, r0, x0, cosPhi)
+		dU_pa := voltageDropVolts(Im_pa, L, r0, x0, cosPhi)
+
+		// Втрати потужності та річні втрати енергії в двох кабелях у нормальному режимі
+		tau := lossTime(Tm)
+		dP := 2 * 3 * math.Pow(Im, 2) * r0 * L / 1000
+		dW := dP * tau
+
+		// 2
+		// Рауємо опори елементів
+		Xc := math.Pow(10.5, 2) / Sk
+		Xt := (t_uk / 100) * (math.Pow(10.5, 2) / t_snom)
+		// Сумарний опір
+		Xe := Xc + Xt
+		// Початкове діюче значення струму трифазного КЗ
+		Ip0 := 10.5 / (math.Sqrt(3) * Xe)
+
+		// 3
+		// Розраховуємо струми КЗ для заданого сценарію мережі
+		sc := calcShortCircuit(scenario)
+		// Струми однофазного та двофазного на землю КЗ в нормальному та мінімальному режимах
+		gf, errG := scenario.groundFaults(scenario.Rcn, scenario.Xcn)
+		if errG != nil {
+			data.Error = "Bad network values: " + errG.Error()
+			respond(w, r, "prac_4_task_1", data, "templates/prac_4_task_1.html")
+			return
+		}
+		gfMin, errG := scenario.groundFaults(scenario.Rcmin, scenario.Xcmin)
+		if errG != nil {
+			data.Error = "Bad network values: " + errG.Error()
+			respond(w, r, "prac_4_task_1", data, "templates/prac_4_task_1.html")
+			return
+		}
+
+		// 4
+		// Ударний струм КЗ на шинах 10 кВ (ударний коефіцієнт визначається відношенням X/R)
+		Ta, Ky, iy := calcPeakCurrent(sc.Ishn_3, sc.Xshn, sc.Rshn)
+
+		// Перевірка кабеля на термічну стійкість: обраний переріз не менший за мінімальний
+		thermalOk := s >= s_min
+
+		// Перевірка шин на термічну стійкість
+		// Як і для динамічної стійкості, використовуємо розрахований струм трифазного КЗ на шинах 10 кВ,
+		// а не заданий струм Iк, що стосується кабеля
+		bus_s := bus_b * bus_h
+		bus_s_min := (sc.Ishn_3 * math.Sqrt(tf)) / material.C
+		busThermalOk := bus_s >= bus_s_min
+
+		// Перевірка шин на динамічну стійкість
+		busCheck := calcBusbarCheck(iy, bus_l, bus_a, bus_b, bus_h, material.SigmaAllow)
+
+		// Для кола без активного опору стала часу нескінченна (JSON не підтримує Inf)
+		var TaRes interface{} = round(Ta, 4)
+		if math.IsInf(Ta, 1) {
+			TaRes = "∞"
+		}
+
+		// Заносимо усі результати у список
+		data.Results = map[string]interface{}{
+			"sek":        round(sek, 2),
+			"jek":        round(jek, 3),
+			"s":          s,
+			"s_governing": selection.Governing,
+			"s_criteria":  selection.Criteria,
+			"cable":       cable.ID + " (" + cable.Name + ")",
+			"derating":    derating,
+			"I_allow":     selection.Section.Iallow,
+			"dU":          round(dU, 2),
+			"dU_pct":      round(dU/(10*1000)*100, 3),
+			"dU_pa":       round(dU_pa, 2),
+			"dU_pa_pct":   round(dU_pa/(10*1000)*100, 3),
+			"tau":         round(tau, 0),
+			"dP":          round(dP, 3),
+			"dW":          round(dW, 0),
+			"Im":         round(Im, 2),
+			"Im_pa":      round(Im_pa, 2),
+			"Ip0":        round(Ip0, 2),
+			"Ish_3":      round(sc.Ish_3, 2),
+			"Ish_2":      round(sc.Ish_2, 2),
+			"Ish_min_3":  round(sc.Ish_min_3, 2),
+			"Ish_min_2":  round(sc.Ish_min_2, 2),
+			"Ishn_3":     round(sc.Ishn_3, 2),
+			"Ishn_2":     round(sc.Ishn_2, 2),
+			"Ishn_min_3": round(sc.Ishn_min_3, 2),
+			"Ishn_min_2": round(sc.Ishn_min_2, 2),
+			"Iln_3":      round(sc.Iln_3, 2),
+			"Iln_2":      round(sc.Iln_2, 2),
+			"Iln_min_3":  round(sc.Iln_min_3, 2),
+			"Iln_min_2":  round(sc.Iln_min_2, 2),
+			// Струми несиметричних КЗ переводимо з кА в А, як і решту струмів пункту 3
+			"connection":  scenario.connection(),
+			"grounded":    gf.Grounded,
+			"Ishn_1":      round(gf.Bus.I1*1000, 2),
+			"Ishn_11":     round(gf.Bus.I11*1000, 2),
+			"Ishn_min_1":  round(gfMin.Bus.I1*1000, 2),
+			"Ishn_min_11": round(gfMin.Bus.I11*1000, 2),
+			"Iln_1":       round(gf.LineEnd.I1*1000, 2),
+			"Iln_11":      round(gf.LineEnd.I11*1000, 2),
+			"Iln_min_1":   round(gfMin.LineEnd.I1*1000, 2),
+			"Iln_min_11":  round(gfMin.LineEnd.I11*1000, 2),
+
+			"C":              C,
+			"s_min":          round(s_min, 2),
+			"thermal_ok":     thermalOk,
+			"Ta":             TaRes,
+			"Ky":             round(Ky, 3),
+			"iy":             round(iy/1000, 2),
+			"bus_material":   material.Name,
+			"bus_s":          round(bus_s, 0),
+			"bus_s_min":      round(bus_s_min, 2),
+			"bus_Ik":         round(sc.Ishn_3, 2),
+			"bus_thermal_ok": busThermalOk,
+			"bus_F":          round(busCheck.F, 1),
+			"bus_W":          round(busCheck.W, 3),
+			"bus_sigma":      round(busCheck.Sigma, 2),
+			"bus_sigma_allow": busCheck.SigmaAllow,
+			"bus_dynamic_ok": busCheck.Ok,
+		}
+
+		// Якщо користувач попросив, додаємо усі проміжні величини з формулами
+		if showDerivation {
+			derivation := []calcStep{
+				{"Iм", Im, "А", "(Sм/2) / (√3·Uном)"},
+				{"Iм.па", Im_pa, "А", "2·Iм"},
+				{"jек", jek, "А/мм²", "таблиця jек(Tм)"},
+				{"sек", sek, "мм²", "Iм / jек"},
+				{"C", C, "А·с^0.5/мм²", "таблиця C(тип кабеля)"},
+				{"smin", s_min, "мм²", "Iк·√tф / C"},
+				{"Kt", derating.Kt, "", "таблиця Kt(ізоляція, прокладання, tсер)"},
+				{"Kn", derating.Kn, "", "таблиця Kn(прокладання, кількість кабелів)"},
+				{"Kпр", derating.Klaying, "", "таблиця Kпр(спосіб прокладання)"},
+				{"K", derating.K, "", "Kt·Kn·Kпр"},
+				{"Iдоп", selection.Section.Iallow, "А", "Iдоп.табл·K"},
+				{"r0", r0, "Ом/км", "таблиця стандартних перерізів (s = " + strconv.FormatFloat(s, 'g', -1, 64) + " мм²)"},
+				{"x0", x0, "Ом/км", "каталог кабелів"},
+				{"ΔU", dU, "В", "√3·Iм·L·(r0·cos φ + x0·sin φ)"},
+				{"ΔU.па", dU_pa, "В", "√3·Iм.па·L·(r0·cos φ + x0·sin φ)"},
+				{"τ", tau, "год", "(0.124 + Tм/10⁴)²·8760"},
+				{"ΔP", dP, "кВт", "2·3·Iм²·r0·L / 1000"},
+				{"ΔW", dW, "кВт·год", "ΔP·τ"},
+				{"Xc", Xc, "Ом", "Uср.ном² / Sк"},
+				{"Xт", Xt, "Ом", "(uк/100)·(Uср.ном² / Sном.т)"},
+				{"XΣ", Xe, "Ом", "Xc + Xт"},
+				{"Iп0", Ip0, "кА", "Uср.ном / (√3·XΣ)"},
+			}
+			derivation = append(derivation, sc.steps(scenario)...)
+			if gf.Grounded {
+				derivation = append(derivation,
+					calcStep{"Z0ш.н", *gf.Bus.Z0ohm, "Ом", "вхідний опір нульової послідовності (" + scenario.connection() + ")"},
+					calcStep{"Iш.н(1)", gf.Bus.I1 * 1000, "А", "3·Uнн·1000 / (√3·|Z1 + Z2 + Z0|)"},
+				)
+				if gf.LineEnd.Z0ohm != nil {
+					derivation = append(derivation,
+						calcStep{"Z0л.н", *gf.LineEnd.Z0ohm, "Ом", "Z0ш.н + lл·Z0л"},
+						calcStep{"Iл.н(1)", gf.LineEnd.I1 * 1000, "А", "3·Uнн·1000 / (√3·|Z1 + Z2 + Z0|)"},
+					)
+				}
+			}
+			// Для кола без активного опору стала часу нескінченна, тому її не виводимо як число
+			if !math.IsInf(Ta, 1) {
+				derivation = append(derivation, calcStep{"Ta", Ta, "с", "Xш.н / (ω·Rш.н), ω = 2π·50"})
+			}
+			derivation = append(derivation,
+				calcStep{"Ky", Ky, "", "1 + e^(-0.01/Ta)"},
+				calcStep{"iy", iy, "А", "√2·Ky·Iш.н(3)"},
+				calcStep{"sш", bus_s, "мм²", "b·h"},
+				calcStep{"sш.min", bus_s_min, "мм²", "Iш.н(3)·√tф / Cш"},
+				calcStep{"F", busCheck.F, "Н", "√3·10⁻⁷·(l/a)·iy²"},
+				calcStep{"M", busCheck.M, "Н·м", "F·l / 10"},
+				calcStep{"W", busCheck.W, "см³", "b·h² / 6"},
+				calcStep{"σрозр", busCheck.Sigma, "МПа", "M / W"},
+			)
+			for i := range derivation {
+				derivation[i].Value = round(derivation[i].Value, 4)
+			}
+			data.Results["derivation"] = derivation
+		}
+	}
+	respond(w, r, "prac_4_task_1", data, "templates/prac_4_task_1.html")
+}
+
+// Допоміжна функція, що перевіряє наявність рядка у списку
+func containsString(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// API Handler для сценаріїв мережі
+// GET повертає усі збережені сценарії, POST (JSON тіло) зберігає новий сценарій
+func prac4ScenariosHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		scenarios, err := getNetworkScenarios()
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, scenarios)
+	case http.MethodPost:
+		var sc networkScenario
+		if err := json.NewDecoder(r.Body).Decode(&sc); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Bad JSON: " + err.Error()})
+			return
+		}
+		if err := saveNetworkScenario(sc); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, sc)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// Вузол (шина) розрахункової схеми мережі
+type networkBus struct {
+	ID  string  `json:"id"`
+	Ukv float64 `json:"u_kv"` // базова (середня номінальна) напруга шини, кВ
+}
+
+// Вітка розрахункової схеми: джерело, трансформатор, лінія або реактор
+// Для джерела поле To не заповнюється (джерело приєднане між шиною та землею)
+type networkBranch struct {
+	Type string `json:"type"` // source, transformer, line, reactor
+	From string `json:"from"`
+	To   string `json:"to,omitempty"`
+
+	// Джерело: потужність КЗ та відношення X/R, або опори в омах
+	SkMVA float64 `json:"sk_mva,omitempty"`
+	XR    float64 `json:"x_r,omitempty"`
+	// Відношення опорів зворотної та нульової послідовностей до прямої (за замовчуванням 1)
+	Z2Z1 float64 `json:"z2_z1,omitempty"`
+	Z0Z1 float64 `json:"z0_z1,omitempty"`
+	// Джерело з ізольованою нейтраллю не має шляху для струмів нульової послідовності
+	Ungrounded bool `json:"ungrounded,omitempty"`
+
+	// Трансформатор: номінальна потужність, напруга КЗ, втрати КЗ
+	// та схема з'єднання обмоток (сторона From - сторона To), наприклад YNd11, Dyn11, YNyn0
+	SnomMVA    float64 `json:"s_mva,omitempty"`
+	UkPct      float64 `json:"uk_pct,omitempty"`
+	PkKW       float64 `json:"pk_kw,omitempty"`
+	Connection string  `json:"connection,omitempty"`
+
+	// Лінія: питомі опори прямої послідовності, нульової послідовності та довжина
+	// Якщо опори нульової послідовності не задані, приймаємо Z0 = 3.5 * Z1
+	R0       float64 `json:"r0_ohm_km,omitempty"`
+	X0       float64 `json:"x0_ohm_km,omitempty"`
+	RZero    float64 `json:"r_zero_ohm_km,omitempty"`
+	XZero    float64 `json:"x_zero_ohm_km,omitempty"`
+	LengthKm float64 `json:"length_km,omitempty"`
+
+	// Реактор (або джерело, задане опорами): опори в омах, приведені до напруги шини From
+	Rohm float64 `json:"r_ohm,omitempty"`
+	Xohm float64 `json:"x_ohm,omitempty"`
+}
+
+// Розрахункова схема мережі для розрахунку струмів КЗ у відносних одиницях
+type networkModel struct {
+	Sbase    float64         `json:"s_base_mva"` // базова потужність, МВ*А
+	C        float64         `json:"c"`          // коефіцієнт напруги джерела (c = 1 за замовчуванням)
+	Buses    []networkBus    `json:"buses"`
+	Branches []networkBranch `json:"branches"`
+}
+
+// Результати розрахунку КЗ на одній шині
+type busFaultResult struct {
+	Bus   string   `json:"bus"`
+	Ukv   float64  `json:"u_kv"`
+	Rohm  float64  `json:"r_ohm"` // вхідний опір схеми прямої послідовності відносно шини, Ом
+	Xohm  float64  `json:"x_ohm"`
+	Zohm  float64  `json:"z_ohm"`
+	Z2ohm float64  `json:"z2_ohm"`           // вхідний опір зворотної послідовності, Ом
+	Z0ohm *float64 `json:"z0_ohm,omitempty"` // вхідний опір нульової послідовності, Ом (відсутній, якщо шлях на землю відсутній)
+	I3    float64  `json:"i3_ka"`            // струм трифазного КЗ, кА
+	I2    float64  `json:"i2_ka"`            // струм двофазного КЗ, кА
+	I1    float64  `json:"i1_ka"`            // струм однофазного КЗ на землю, кА
+	I11   float64  `json:"i11_ka"`           // найбільший фазний струм двофазного КЗ на землю, кА
+	I11g  float64  `json:"i11_ground_ka"`    // струм у землю (3*I0) при двофазному КЗ на землю, кА
+	Sk    float64  `json:"sk_mva"`           // потужність трифазного КЗ, МВ*А
+}
+
+// Вітка схеми однієї послідовності (to == -1 означає приєднання до землі)
+type seqBranch struct {
+	from, to int
+	z        complex128
+}
+
+// Метод, що перевіряє схему та повертає індекси шин
+func (m *networkModel) busIndex() (map[string]int, error) {
+	if m.Sbase <= 0 {
+		m.Sbase = 100
+	}
+	if m.C <= 0 {
+		m.C = 1
+	}
+	if len(m.Buses) == 0 {
+		return nil, fmt.Errorf("схема не містить жодної шини")
+	}
+
+	index := make(map[string]int, len(m.Buses))
+	for i, b := range m.Buses {
+		if b.ID == "" {
+			return nil, fmt.Errorf("шина #%d не має ідентифікатора", i+1)
+		}
+		if _, ok := index[b.ID]; ok {
+			return nil, fmt.Errorf("шина %q зустрічається двічі", b.ID)
+		}
+		if b.Ukv <= 0 {
+			return nil, fmt.Errorf("напруга шини %q має бути більше 0", b.ID)
+		}
+		index[b.ID] = i
+	}
+	return index, nil
+}
+
+// Метод, що розраховує опір вітки прямої послідовності у відносних одиницях
+// baseKV - базова напруга шини From
+func (b networkBranch) impedancePU(Sbase, baseKV float64) (complex128, error) {
+	Zbase := baseKV * baseKV / Sbase
+
+	switch b.Type {
+	case "source":
+		if b.SkMVA > 0 {
+			Z := Sbase / b.SkMVA
+			if b.XR <= 0 {
+				return complex(0, Z), nil
+			}
+			X := Z / math.Sqrt(1+1/(b.XR*b.XR))
+			return complex(X/b.XR, X), nil
+		}
+		if b.Rohm > 0 || b.Xohm > 0 {
+			return complex(b.Rohm/Zbase, b.Xohm/Zbase), nil
+		}
+		return 0, fmt.Errorf("джерело на шині %q: потрібно задати sk_mva або r_ohm/x_ohm", b.From)
+	case "transformer":
+		if b.SnomMVA <= 0 || b.UkPct <= 0 {
+			return 0, fmt.Errorf("трансформатор %s-%s: потрібно задати s_mva та uk_pct", b.From, b.To)
+		}
+		Z := b.UkPct / 100 * Sbase / b.SnomMVA
+		R := b.PkKW / 1000 / b.SnomMVA * Sbase / b.SnomMVA
+		if R >= Z {
+			return 0, fmt.Errorf("трансформатор %s-%s: втрати КЗ занадто великі для заданої uk", b.From, b.To)
+		}
+		return complex(R, math.Sqrt(Z*Z-R*R)), nil
+	case "line":
+		if b.LengthKm <= 0 {
+			return 0, fmt.Errorf("лінія %s-%s: довжина має бути більше 0", b.From, b.To)
+		}
+		return complex(b.R0*b.LengthKm/Zbase, b.X0*b.LengthKm/Zbase), nil
+	case "reactor":
+		return complex(b.Rohm/Zbase, b.Xohm/Zbase), nil
+	}
+	return 0, fmt.Errorf("невідомий тип вітки %q", b.Type)
+}
+
+// Метод, що розбирає схему з'єднання обмоток трансформатора (наприклад YNd11)
+// та повертає тип обмотки зі сторони From та зі сторони To: YN, Y або D
+func parseWindingConnection(connection string) (string, string, error) {
+	conn := strings.ToUpper(strings.TrimSpace(connection))
+	if conn == "" {
+		conn = "YND"
+	}
+	winding := func(s string) (string, string) {
+		switch {
+		case strings.HasPrefix(s, "YN"):
+			return "YN", s[2:]
+		case strings.HasPrefix(s, "Y"):
+			return "Y", s[1:]
+		case strings.HasPrefix(s, "D"):
+			return "D", s[1:]
+		}
+		return "", s
+	}
+	from, rest := winding(conn)
+	to, rest := winding(rest)
+	if from == "" || to == "" || strings.Trim(rest, "0123456789") != "" {
+		return "", "", fmt.Errorf("невідома схема з'єднання обмоток %q", connection)
+	}
+	return from, to, nil
+}
+
+// Метод, що формує вітки схеми заданої послідовності (1 - пряма, 2 - зворотна, 0 - нульова)
+func (m *networkModel) sequenceBranches(index map[string]int, seq int) ([]seqBranch, error) {
+	var branches []seqBranch
+	hasSource := false
+
+	ratio := func(v float64) complex128 {
+		if v <= 0 {
+			return 1
+		}
+		return complex(v, 0)
+	}
+
+	for _, b := range m.Branches {
+		from, ok := index[b.From]
+		if !ok {
+			return nil, fmt.Errorf("вітка %s посилається на невідому шину %q", b.Type, b.From)
+		}
+		Z, err := b.impedancePU(m.Sbase, m.Buses[from].Ukv)
+		if err != nil {
+			return nil, err
+		}
+		if Z == 0 {
+			return nil, fmt.Errorf("вітка %s %s-%s має нульовий опір", b.Type, b.From, b.To)
+		}
+
+		// Джерело приєднане між шиною та землею
+		if b.Type == "source" {
+			hasSource = true
+			switch seq {
+			case 2:
+				branches = append(branches, seqBranch{from, -1, Z * ratio(b.Z2Z1)})
+			case 0:
+				if !b.Ungrounded {
+					branches = append(branches, seqBranch{from, -1, Z * ratio(b.Z0Z1)})
+				}
+			default:
+				branches = append(branches, seqBranch{from, -1, Z})
+			}
+			continue
+		}
+
+		to, ok := index[b.To]
+		if !ok {
+			return nil, fmt.Errorf("вітка %s посилається на невідому шину %q", b.Type, b.To)
+		}
+		if to == from {
+			return nil, fmt.Errorf("вітка %s з'єднує шину %q саму з собою", b.Type, b.From)
+		}
+
+		if seq != 0 {
+			branches = append(branches, seqBranch{from, to, Z})
+			continue
+		}
+
+		// Схема нульової послідовності
+		switch b.Type {
+		case "line":
+			Z0 := 3.5 * Z
+			if b.RZero > 0 || b.XZero > 0 {
+				Zbase := m.Buses[from].Ukv * m.Buses[from].Ukv / m.Sbase
+				Z0 = complex(b.RZero*b.LengthKm/Zbase, b.XZero*b.LengthKm/Zbase)
+			}
+			branches = append(branches, seqBranch{from, to, Z0})
+		case "transformer":
+			fromWinding, toWinding, err := parseWindingConnection(b.Connection)
+			if err != nil {
+				return nil, err
+			}
+			Z0 := Z * ratio(b.Z0Z1)
+			switch {
+			case fromWinding == "YN" && toWinding == "YN":
+				// Обидві нейтралі заземлені: струм нульової послідовності проходить через трансформатор
+				branches = append(branches, seqBranch{from, to, Z0})
+			case fromWinding == "YN" && toWinding == "D":
+				// Трикутник замикає струм нульової послідовності: трансформатор - шунт на землю зі сторони YN
+				branches = append(branches, seqBranch{from, -1, Z0})
+			case fromWinding == "D" && toWinding == "YN":
+				branches = append(branches, seqBranch{to, -1, Z0})
+			}
+			// Інші схеми (Y без заземлення нейтралі, Dd) розривають схему нульової послідовності
+		default:
+			branches = append(branches, seqBranch{from, to, Z})
+		}
+	}
+
+	if !hasSource {
+		return nil, fmt.Errorf("схема не містить жодного джерела")
+	}
+	return branches, nil
+}
+
+// Метод, що будує матрицю вузлових опорів Zbus для схеми однієї послідовності
+// Шини, що не мають шляху до землі, позначаються як grounded[i] = false (вхідний опір нескінченний)
+func buildZbus(n int, branches []seqBranch) ([][]complex128, []bool, error) {
+	// Знаходимо шини, пов'язані з землею через вітки схеми
+	adjacent := make([][]int, n)
+	grounded := make([]bool, n)
+	var queue []int
+	for _, b := range branches {
+		if b.to == -1 {
+			if !grounded[b.from] {
+				grounded[b.from] = true
+				queue = append(queue, b.from)
+			}
+			continue
+		}
+		adjacent[b.from] = append(adjacent[b.from], b.to)
+		adjacent[b.to] = append(adjacent[b.to], b.from)
+	}
+	for len(queue) > 0 {
+		bus := queue[0]
+		queue = queue[1:]
+		for _, next := range adjacent[bus] {
+			if !grounded[next] {
+				grounded[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	// Будуємо матрицю вузлових провідностей лише для шин, пов'язаних з землею
+	position := make([]int, n)
+	var buses []int
+	for i := 0; i < n; i++ {
+		position[i] = -1
+		if grounded[i] {
+			position[i] = len(buses)
+			buses = append(buses, i)
+		}
+	}
+
+	Z := make([][]complex128, n)
+	for i := range Z {
+		Z[i] = make([]complex128, n)
+	}
+	if len(buses) == 0 {
+		return Z, grounded, nil
+	}
+
+	Y := make([][]complex128, len(buses))
+	for i := range Y {
+		Y[i] = make([]complex128, len(buses))
+	}
+	for _, b := range branches {
+		from := position[b.from]
+		if from == -1 {
+			continue
+		}
+		y := 1 / b.z
+		Y[from][from] += y
+		if b.to == -1 {
+			continue
+		}
+		to := position[b.to]
+		Y[to][to] += y
+		Y[from][to] -= y
+		Y[to][from] -= y
+	}
+
+	inv, err := invertComplexMatrix(Y)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i, bi := range buses {
+		for j, bj := range buses {
+			Z[bi][bj] = inv[i][j]
+		}
+	}
+	return Z, grounded, nil
+}
+
+// Метод, що обертає комплексну матрицю методом Гауса-Жордана
+// (використовується для отримання матриці вузлових опорів Zbus = Ybus^-1)
+func invertComplexMatrix(a [][]complex128) ([][]complex128, error) {
+	n := len(a)
+	// Розширена матриця [A | E]
+	aug := make([][]complex128, n)
+	for i := range a {
+		aug[i] = make([]complex128, 2*n)
+		copy(aug[i], a[i])
+		aug[i][n+i] = 1
+	}
+
+	for col := 0; col < n; col++ {
+		// Вибір головного елемента по стовпцю
+		pivot := col
+		for row := col + 1; row < n; row++ {
+			if cmplxAbs(aug[row][col]) > cmplxAbs(aug[pivot][col]) {
+				pivot = row
+			}
+		}
+		if cmplxAbs(aug[pivot][col]) < 1e-12 {
+			return nil, fmt.Errorf("матриця вироджена: перевірте, що кожна шина пов'язана з джерелом")
+		}
+		aug[col], aug[pivot] = aug[pivot], aug[col]
+
+		p := aug[col][col]
+		for j := range aug[col] {
+			aug[col][j] /= p
+		}
+		for row := 0; row < n; row++ {
+			if row == col || aug[row][col] == 0 {
+				continue
+			}
+			f := aug[row][col]
+			for j := range aug[row] {
+				aug[row][j] -= f * aug[col][j]
+			}
+		}
+	}
+
+	inv := make([][]complex128, n)
+	for i := range aug {
+		inv[i] = aug[i][n:]
+	}
+	return inv, nil
+}
+
+// Модуль комплексного числа
+func cmplxAbs(z complex128) float64 {
+	return math.Hypot(real(z), imag(z))
+}
+
+// Метод, що розраховує струми трифазного, двофазного, однофазного та двофазного на землю КЗ
+// на кожній шині схеми методом симетричних складових.
+// Значення за замовчуванням (Sбаз, c) записуються у передану схему, щоб їх можна було показати користувачу
+func solveNetworkFaults(m *networkModel) ([]busFaultResult, error) {
+	index, err := m.busIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	// Матриці вузлових опорів прямої, зворотної та нульової послідовностей
+	var Zbus [3][][]complex128
+	var grounded [3][]bool
+	for _, seq := range []int{1, 2, 0} {
+		branches, err := m.sequenceBranches(index, seq)
+		if err != nil {
+			return nil, err
+		}
+		Zbus[seq], grounded[seq], err = buildZbus(len(m.Buses), branches)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	E := complex(m.C, 0)
+	// Оператор повороту a = e^(j120°)
+	a := complex(-0.5, math.Sqrt(3)/2)
+
+	results := make([]busFaultResult, 0, len(m.Buses))
+	for i, bus := range m.Buses {
+		if !grounded[1][i] {
+			return nil, fmt.Errorf("шина %q не пов'язана з жодним джерелом", bus.ID)
+		}
+		Z1 := Zbus[1][i][i]
+		Z2 := Zbus[2][i][i]
+		Zbase := bus.Ukv * bus.Ukv / m.Sbase
+		Ibase := m.Sbase / (math.Sqrt(3) * bus.Ukv)
+
+		// Струм трифазного КЗ: I = c / |Z1| у відносних одиницях
+		I3 := cmplxAbs(E/Z1) * Ibase
+		// Струм двофазного КЗ: I = √3 * c / |Z1 + Z2|
+		I2 := math.Sqrt(3) * cmplxAbs(E/(Z1+Z2)) * Ibase
+
+		res := busFaultResult{
+			Bus:   bus.ID,
+			Ukv:   bus.Ukv,
+			Rohm:  round(real(Z1)*Zbase, 4),
+			Xohm:  round(imag(Z1)*Zbase, 4),
+			Zohm:  round(cmplxAbs(Z1)*Zbase, 4),
+			Z2ohm: round(cmplxAbs(Z2)*Zbase, 4),
+			I3:    round(I3, 3),
+			I2:    round(I2, 3),
+			Sk:    round(math.Sqrt(3)*bus.Ukv*I3, 2),
+		}
+
+		if grounded[0][i] {
+			Z0 := Zbus[0][i][i]
+			z0 := round(cmplxAbs(Z0)*Zbase, 4)
+			res.Z0ohm = &z0
+
+			// Однофазне КЗ на землю: I = 3 * c / |Z1 + Z2 + Z0|
+			res.I1 = round(3*cmplxAbs(E/(Z1+Z2+Z0))*Ibase, 3)
+
+			// Двофазне КЗ на землю (фази B і C)
+			Ia1 := E / (Z1 + Z2*Z0/(Z2+Z0))
+			Ia2 := -Ia1 * Z0 / (Z2 + Z0)
+			Ia0 := -Ia1 * Z2 / (Z2 + Z0)
+			Ib := Ia0 + a*a*Ia1 + a*Ia2
+			Ic := Ia0 + a*Ia1 + a*a*Ia2
+			res.I11 = round(math.Max(cmplxAbs(Ib), cmplxAbs(Ic))*Ibase, 3)
+			res.I11g = round(3*cmplxAbs(Ia0)*Ibase, 3)
+		} else {
+			// Шлях для струмів нульової послідовності відсутній (ізольована нейтраль):
+			// однофазне КЗ не дає струму КЗ, а двофазне на землю зводиться до двофазного
+			res.I11 = res.I2
+		}
+
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+// Метод, що читає приклад схеми мережі з файлу (використовується як значення за замовчуванням)
+func getNetworkExample() (string, error) {
+	content, err := os.ReadFile("./instance/prac_4_network_example.json")
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// Шлях, що обробляє розрахунок струмів КЗ для довільної схеми мережі
+// Приймає схему у вигляді JSON (поле форми network або тіло запиту з Content-Type: application/json)
+func prac4Network(w http.ResponseWriter, r *http.Request) {
+	example, err := getNetworkExample()
+	if err != nil {
+		http.Error(w, "Failed to load network example: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	data := PageData{
+		IsIndex:       false,
+		DefaultValues: map[string]interface{}{"network": example},
+	}
+
+	if r.Method == http.MethodPost {
+		var model networkModel
+
+		// Для API запитів схема передається безпосередньо у тілі запиту
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+			if err := json.NewDecoder(r.Body).Decode(&model); err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Bad JSON: " + err.Error()})
+				return
+			}
+			results, err := solveNetworkFaults(&model)
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]interface{}{"buses": results, "Sbase": model.Sbase, "c": model.C})
+			return
+		}
+
+		networkStr := r.FormValue("network")
+		data.DefaultValues["network"] = networkStr
+		if err := json.Unmarshal([]byte(networkStr), &model); err != nil {
+			data.Error = "Bad network JSON: " + err.Error()
+			respond(w, r, "prac_4_network", data, "templates/prac_4_network.html")
+			return
+		}
+
+		results, err := solveNetworkFaults(&model)
+		if err != nil {
+			data.Error = "Network error: " + err.Error()
+			respond(w, r, "prac_4_network", data, "templates/prac_4_network.html")
+			return
+		}
+
+		data.Results = map[string]interface{}{
+			"buses": results,
+			"Sbase": model.Sbase,
+			"c":     model.C,
+		}
+	}
+
+	respond(w, r, "prac_4_network", data, "templates/prac_4_network.html")
+}
+
+const prac5ElementsFile = "./instance/prac_5_data.json"
+
+// Захищає каталог елементів ЕПС від одночасного читання-зміни-запису кількома запитами
+var prac5ElementsMu sync.Mutex
+
+// Типи елементів електропостачальної системи та їх назви для інтерфейсу
+var reliabilityElementTypes = map[string]string{
+	"overhead_line": "Повітряна лінія",
+	"cable_line":    "Кабельна лінія",
+	"transformer":   "Трансформатор",
+	"breaker":       "Вимикач",
+	"busbar":        "Збірні шини",
+	"motor":         "Електродвигун",
+}
+
+// Порядок, в якому групи елементів виводяться користувачу
+var reliabilityElementTypeOrder = []string{"overhead_line", "cable_line", "transformer", "breaker", "busbar", "motor"}
+
+// Одиниці вимірювання параметрів елементів ЕПС
+var reliabilityElementUnits = map[string]string{
+	"voltage_kv": "кВ",
+	"omega":      "рік^-1",
+	"tv":         "год",
+	"tp":         "год",
+}
+
+// Група елементів ЕПС одного типу
+type reliabilityElementGroup struct {
+	Type     string               `json:"type"`
+	Label    string               `json:"label"`
+	Elements []reliabilityElement `json:"elements"`
+}
+
+// Метод, що групує елементи за типом та сортує їх за класом напруги (від вищого) і назвою
+func groupPrac5Elements(elements []reliabilityElement) []reliabilityElementGroup {
+	sorted := append([]reliabilityElement(nil), elements...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].VoltageKV != sorted[j].VoltageKV {
+			return sorted[i].VoltageKV > sorted[j].VoltageKV
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	groups := []reliabilityElementGroup{}
+	for _, t := range reliabilityElementTypeOrder {
+		group := reliabilityElementGroup{Type: t, Label: reliabilityElementTypes[t]}
+		for _, e := range sorted {
+			if e.Type == t {
+				group.Elements = append(group.Elements, e)
+			}
+		}
+		if len(group.Elements) > 0 {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// Елемент електропостачальної системи з показниками надійності
+type reliabilityElement struct {
+	Name      string  `json:"name"`
+	Type      string  `json:"type"`
+	VoltageKV float64 `json:"voltage_kv"` // клас напруги, кВ
+	Omega     float64 `json:"omega"`      // частота відмов, рік^-1
+	Tv        float64 `json:"tv"`         // середня тривалість відновлення, год
+	Tp        float64 `json:"tp"`         // середня тривалість планового простою, год
+}
+
+// Метод, що перевіряє, чи задані усі поля елемента
+func (e reliabilityElement) validate() error {
+	if strings.TrimSpace(e.Name) == "" {
+		return fmt.Errorf("назва елемента не може бути порожньою")
+	}
+	if _, ok := reliabilityElementTypes[e.Type]; !ok {
+		return fmt.Errorf("невідомий тип елемента %q", e.Type)
+	}
+	if e.VoltageKV <= 0 {
+		return fmt.Errorf("клас напруги має бути більше 0")
+	}
+	if e.Omega <= 0 {
+		return fmt.Errorf("частота відмов має бути більше 0")
+	}
+	if e.Tv <= 0 {
+		return fmt.Errorf("тривалість відновлення має бути більше 0")
+	}
+	if e.Tp < 0 {
+		return fmt.Errorf("тривалість планового простою не може бути від'ємною")
+	}
+	return nil
+}
+
+// Метод, що читає каталог елементів ЕПС з файлу
+func getPrac5Elements() ([]reliabilityElement, error) {
+	file, err := os.Open(prac5ElementsFile)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var elements []reliabilityElement
+	if err := json.NewDecoder(file).Decode(&elements); err != nil {
+		return nil, err
+	}
+	return elements, nil
+}
+
+// Метод, що повертає каталог елементів ЕПС у вигляді карти для пошуку за назвою
+func getPrac5Data() (map[string]reliabilityElement, error) {
+	elements, err := getPrac5Elements()
+	if err != nil {
+		return nil, err
+	}
+	data := make(map[string]reliabilityElement, len(elements))
+	for _, e := range elements {
+		data[e.Name] = e
+	}
+	return data, nil
+}
+
+// Метод, що додає новий або оновлює існуючий елемент ЕПС
+// originalName - назва елемента до редагування (порожня для нового елемента)
+func savePrac5Element(e reliabilityElement, originalName string) error {
+	e.Name = strings.TrimSpace(e.Name)
+	if err := e.validate(); err != nil {
+		return err
+	}
+	// Без старої назви елемент додається або оновлюється за своєю назвою,
+	// а при редагуванні елемент зі старою назвою має існувати
+	editing := originalName != ""
+	if !editing {
+		originalName = e.Name
+	}
+	// Секційний вимикач використовується в розрахунку двоколової системи, тому його можна лише редагувати
+	if originalName == sectionalBreakerName && (e.Name != sectionalBreakerName || e.Type != "breaker") {
+		return fmt.Errorf("елемент %q використовується як секційний вимикач, його не можна перейменувати або змінити тип", sectionalBreakerName)
+	}
+
+	prac5ElementsMu.Lock()
+	defer prac5ElementsMu.Unlock()
+
+	elements, err := getPrac5Elements()
+	if err != nil {
+		return err
+	}
+
+	replaced := false
+	for i := range elements {
+		if elements[i].Name == e.Name && e.Name != originalName {
+			return fmt.Errorf("елемент %q вже існує", e.Name)
+		}
+	}
+	for i := range elements {
+		if elements[i].Name == originalName {
+			elements[i] = e
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		if editing {
+			return fmt.Errorf("елемент %q не знайдено", originalName)
+		}
+		elements = append(elements, e)
+	}
+	return writeJSONFile(prac5ElementsFile, elements)
+}
+
+// Метод, що видаляє елемент ЕПС з каталогу
+func deletePrac5Element(name string) error {
+	if name == sectionalBreakerName {
+		return fmt.Errorf("елемент %q використовується як секційний вимикач і не може бути видалений", name)
+	}
+
+	prac5ElementsMu.Lock()
+	defer prac5ElementsMu.Unlock()
+
+	elements, err := getPrac5Elements()
+	if err != nil {
+		return err
+	}
+	for i := range elements {
+		if elements[i].Name == name {
+			return writeJSONFile(prac5ElementsFile, append(elements[:i], elements[i+1:]...))
+		}
+	}
+	return fmt.Errorf("елемент %q не знайдено", name)
+}
+
+// Метод, що отримує елемент ЕПС з форми
+func getPrac5ElementFromForm(r *http.Request) (reliabilityElement, error) {
+	var e reliabilityElement
+	var errs [4]error
+	e.Name = strings.TrimSpace(r.FormValue("name"))
+	e.Type = r.FormValue("type")
+	e.VoltageKV, errs[0] = getFloat(r, "voltage_kv")
+	e.Omega, errs[1] = getFloat(r, "omega")
+	e.Tv, errs[2] = getFloat(r, "tv")
+	e.Tp, errs[3] = getFloat(r, "tp")
+	for _, err := range errs {
+		if err != nil {
+			return e, fmt.Errorf("усі числові поля мають бути заповнені")
+		}
+	}
+	return e, e.validate()
+}
+
+// API Handler для отримання списку елементів, згрупованих за типом
+// Підтримує фільтри ?voltage= (клас напруги, кВ) та ?type= (тип елемента)
+func prac5DataHandler(w http.ResponseWriter, r *http.Request) {
+	elements, err := getPrac5Elements()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	typeFilter := r.URL.Query().Get("type")
+	if _, ok := reliabilityElementTypes[typeFilter]; typeFilter != "" && !ok {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("unknown element type %q", typeFilter)})
+		return
+	}
+	var voltage float64
+	if r.URL.Query().Get("voltage") != "" {
+		voltage, err = getFloat(r, "voltage")
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Bad values: check voltage"})
+			return
+		}
+	}
+
+	var filtered []reliabilityElement
+	for _, e := range elements {
+		if (typeFilter != "" && e.Type != typeFilter) || (voltage > 0 && e.VoltageKV != voltage) {
+			continue
+		}
+		filtered = append(filtered, e)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"units":  reliabilityElementUnits,
+		"groups": groupPrac5Elements(filtered),
+	})
+}
+
+// API для керування каталогом елементів ЕПС
+// GET - список елементів, POST - додавання/оновлення (JSON), DELETE ?name= - видалення
+func prac5ElementsAPI(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		elements, err := getPrac5Elements()
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, elements)
+	case http.MethodPost, http.MethodPut:
+		var e reliabilityElement
+		decoder := json.NewDecoder(r.Body)
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&e); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Bad JSON: " + err.Error()})
+			return
+		}
+		// При редагуванні стара назва елемента передається параметром ?name=
+		if err := savePrac5Element(e, r.URL.Query().Get("name")); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, e)
+	case http.MethodDelete:
+		if err := deletePrac5Element(r.URL.Query().Get("name")); err != nil {
+			status := http.StatusNotFound
+			if r.URL.Query().Get("name") == sectionalBreakerName {
+				status = http.StatusBadRequest
+			}
+			writeJSON(w, status, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"deleted": r.URL.Query().Get("name")})
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// Шлях, що обробляє сторінку керування каталогом елементів ЕПС
+func prac5Elements(w http.ResponseWriter, r *http.Request) {
+	// Запити з JSON тілом, DELETE та ?format=json обробляються як API
+	if wantsJSON(r) || r.Method == http.MethodDelete || strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+		prac5ElementsAPI(w, r)
+		return
+	}
+
+	defaultValues := map[string]interface{}{
+		"types":         reliabilityElementTypes,
+		"original_name": "",
+		"name":          "",
+		"type":          "",
+		"voltage_kv":    "",
+		"omega":         "",
+		"tv":            "",
+		"tp":            "",
+	}
+	data := PageData{IsIndex: false, DefaultValues: defaultValues}
+
+	if r.Method == http.MethodPost {
+		var err error
+		if r.FormValue("action") == "delete" {
+			err = deletePrac5Element(r.FormValue("original_name"))
+		} else {
+			var e reliabilityElement
+			e, err = getPrac5ElementFromForm(r)
+			if err == nil {
+				err = savePrac5Element(e, r.FormValue("original_name"))
+			}
+			if err != nil {
+				// Залишаємо введені дані у формі, щоб користувач міг їх виправити
+				defaultValues["original_name"] = r.FormValue("original_name")
+				for _, key := range []string{"name", "type", "voltage_kv", "omega", "tv", "tp"} {
+					defaultValues[key] = r.FormValue(key)
+				}
+			}
+		}
+		if err != nil {
+			data.Error = "Element error: " + err.Error()
+		}
+	} else if name := r.URL.Query().Get("edit"); name != "" {
+		// Заповнюємо форму даними елемента, який редагується
+		pracData, err := getPrac5Data()
+		if err != nil {
+			data.Error = "Error reading data file"
+		} else if e, ok := pracData[name]; ok {
+			defaultValues["original_name"] = e.Name
+			defaultValues["name"] = e.Name
+			defaultValues["type"] = e.Type
+			defaultValues["voltage_kv"] = e.VoltageKV
+			defaultValues["omega"] = e.Omega
+			defaultValues["tv"] = e.Tv
+			defaultValues["tp"] = e.Tp
+		} else {
+			data.Error = fmt.Sprintf("Element error: елемент %q не знайдено", name)
+		}
+	}
+
+	elements, err := getPrac5Elements()
+	if err != nil {
+		data.Error = "Error reading data file"
+	}
+	defaultValues["elements"] = elements
+
+	render(w, "prac_5_elements", data, "templates/prac_5_elements.html")
+}
+
+// Блок структурної схеми надійності
+// type: element (елемент каталогу), series (послідовне з'єднання),
+// parallel (паралельне з'єднання), k_of_n (працює, якщо справні щонайменше k з n блоків)
+type schemeBlock struct {
+	Type    string        `json:"type"`
+	Name    string        `json:"name,omitempty"`
+	Element string        `json:"element,omitempty"` // назва елемента каталогу (для type=element)
+	Count   int           `json:"count,omitempty"`   // кількість однакових елементів, з'єднаних послідовно
+	K       int           `json:"k,omitempty"`       // мінімальна кількість справних блоків (для type=k_of_n)
+	Blocks  []schemeBlock `json:"blocks,omitempty"`
+
+	// Щорічний плановий простій блоку (враховується лише при моделюванні методом Монте-Карло)
+	PlannedHours float64 `json:"planned_hours,omitempty"` // тривалість, год
+	PlannedStart float64 `json:"planned_start,omitempty"` // початок від початку року, год
+}
+
+// Показники надійності блоку схеми
+type blockReliability struct {
+	Label string  `json:"label"`
+	Depth int     `json:"depth"`
+	F     float64 `json:"f"`    // частота відмов, рік^-1
+	A     float64 `json:"a"`    // коефіцієнт готовності
+	U     float64 `json:"u"`    // коефіцієнт аварійного простою
+	Tv    float64 `json:"tv"`   // середня тривалість відновлення, год
+	MTBF  float64 `json:"mtbf"` // середній час між відмовами, років
+}
+
+// Максимальна кількість блоків k_of_n (стани перебираються повністю, 2^n)
+const maxKofNBlocks = 16
+
+// Метод, що заповнює похідні показники блоку за коефіцієнтом готовності та частотою відмов
+func newBlockReliability(label string, depth int, A, f float64) blockReliability {
+	res := blockReliability{Label: label, Depth: depth, F: f, A: A, U: 1 - A}
+	if f > 0 {
+		res.Tv = res.U * 8760 / f
+		res.MTBF = 1 / f
+	}
+	return res
+}
+
+// Метод, що розраховує коефіцієнт готовності та частоту відмов блоку схеми методом частот і тривалостей
+// Кожен блок зводиться до еквівалентного елемента з двома станами; показники усіх блоків заносяться в out
+func solveScheme(b schemeBlock, catalog map[string]reliabilityElement, depth int, out *[]blockReliability) (float64, float64, error) {
+	// Резервуємо рядок для блоку, щоб він стояв перед своїми складовими
+	row := len(*out)
+	*out = append(*out, blockReliability{})
+
+	var A, f float64
+	label := b.Name
+	switch b.Type {
+	case "element":
+		e, ok := catalog[b.Element]
+		if !ok {
+			return 0, 0, fmt.Errorf("елемент %q відсутній у каталозі", b.Element)
+		}
+		count := b.Count
+		if count == 0 {
+			count = 1
+		}
+		if count < 0 {
+			return 0, 0, fmt.Errorf("кількість елементів %q не може бути від'ємною", b.Element)
+		}
+		if label == "" {
+			label = e.Name
+		}
+		if count > 1 {
+			label = fmt.Sprintf("%s × %d", label, count)
+		}
+		// Готовність одного елемента: A = μ/(λ+μ), де μ = 8760/tв
+		mu := 8760 / e.Tv
+		a1 := mu / (e.Omega + mu)
+		A = math.Pow(a1, float64(count))
+		f = A * float64(count) * e.Omega
+	case "series", "parallel", "k_of_n":
+		if len(b.Blocks) == 0 {
+			return 0, 0, fmt.Errorf("блок %q не містить складових", b.Type)
+		}
+		As := make([]float64, len(b.Blocks))
+		fs := make([]float64, len(b.Blocks))
+		for i, child := range b.Blocks {
+			var err error
+			As[i], fs[i], err = solveScheme(child, catalog, depth+1, out)
+			if err != nil {
+				return 0, 0, err
+			}
+		}
+
+		switch b.Type {
+		case "series":
+			// Схема відмовляє при відмові будь-якого блоку: f = A·Σ(fі/Aі)
+			A = 1
+			var sum float64
+			for i := range As {
+				A *= As[i]
+				if As[i] > 0 {
+					sum += fs[i] / As[i]
+				}
+			}
+			// Блок, що ніколи не працює (Aі = 0), робить непрацездатною всю схему: відмов з робочого стану немає
+			f = A * sum
+			if label == "" {
+				label = "Послідовне з'єднання"
+			}
+		case "parallel":
+			// Схема відмовляє лише при відмові усіх блоків: f = U·Σ(fі/Uі)
+			U := 1.0
+			var sum float64
+			for i := range As {
+				U *= 1 - As[i]
+				if As[i] < 1 {
+					sum += fs[i] / (1 - As[i])
+				}
+			}
+			// Блок, що ніколи не відмовляє (Uі = 0), робить схему безвідмовною: U = 0, f = 0
+			A = 1 - U
+			f = U * sum
+			if label == "" {
+				label = "Паралельне з'єднання"
+			}
+		case "k_of_n":
+			n := len(As)
+			if b.K < 1 || b.K > n {
+				return 0, 0, fmt.Errorf("k має бути від 1 до %d", n)
+			}
+			if n > maxKofNBlocks {
+				return 0, 0, fmt.Errorf("k_of_n підтримує не більше %d блоків", maxKofNBlocks)
+			}
+			// Перебираємо усі стани; система переходить у стан відмови лише зі станів,
+			// в яких справні рівно k блоків, при відмові одного з них (інтенсивність λі = fі/Aі)
+			for state := 0; state < 1<<n; state++ {
+				P := 1.0
+				up := 0
+				var lambda float64
+				for i := 0; i < n; i++ {
+					if state&(1<<i) != 0 {
+						P *= As[i]
+						up++
+						if As[i] > 0 {
+							lambda += fs[i] / As[i]
+						}
+					} else {
+						P *= 1 - As[i]
+					}
+				}
+				if up >= b.K {
+					A += P
+				}
+				// Неможливі стани (P = 0) не дають переходів у стан відмови
+				if up == b.K && P > 0 {
+					f += P * lambda
+				}
+			}
+			if label == "" {
+				label = fmt.Sprintf("%d з %d", b.K, n)
+			}
+		}
+	default:
+		return 0, 0, fmt.Errorf("невідомий тип блоку %q", b.Type)
+	}
+
+	if math.IsNaN(A) || math.IsInf(A, 0) || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, 0, fmt.Errorf("показники блоку %q не вдалося розрахувати: перевірте параметри елементів", label)
+	}
+	(*out)[row] = newBlockReliability(label, depth, A, f)
+	return A, f, nil
+}
+
+// Межі кількості років моделювання методом Монте-Карло
+const (
+	minSimulationYears = 100
+	maxSimulationYears = 200000
+)
+
+// Найбільший добуток кількості компонентів схеми на кількість років моделювання
+// (кожна подія перевіряє усі компоненти, тому тривалість моделювання пропорційна цьому добутку)
+const maxSimulationComponentYears = 5000000
+
+// Метод, що рахує кількість фізичних елементів схеми (з урахуванням count) без побудови дерева моделювання
+func countSchemeComponents(b schemeBlock) float64 {
+	if b.Type == "element" {
+		if b.Count <= 0 {
+			return 1
+		}
+		return float64(b.Count)
+	}
+	var total float64
+	for _, child := range b.Blocks {
+		total += countSchemeComponents(child)
+	}
+	return total
+}
+
+// Вузол схеми для моделювання: блок з посиланнями на змодельовані компоненти
+type simNode struct {
+	block      schemeBlock
+	components []int // індекси компонентів (для type=element, по одному на кожен з count елементів)
+	children   []*simNode
+	plannedOut bool // блок зараз у плановому простої
+}
+
+// Компонент схеми для моделювання: один фізичний елемент каталогу
+type simComponent struct {
+	omega, tv  float64
+	up         bool
+	nextChange float64 // час наступної зміни стану, год
+}
+
+// Метод, що будує дерево вузлів для моделювання та список компонентів
+func buildSimNode(b schemeBlock, catalog map[string]reliabilityElement, components *[]simComponent) (*simNode, error) {
+	node := &simNode{block: b}
+	if b.PlannedHours < 0 || b.PlannedHours >= 8760 || b.PlannedStart < 0 || b.PlannedStart >= 8760 {
+		return nil, fmt.Errorf("плановий простій має бути в межах року")
+	}
+	switch b.Type {
+	case "element":
+		e, ok := catalog[b.Element]
+		if !ok {
+			return nil, fmt.Errorf("елемент %q відсутній у каталозі", b.Element)
+		}
+		count := b.Count
+		if count == 0 {
+			count = 1
+		}
+		for i := 0; i < count; i++ {
+			node.components = append(node.components, len(*components))
+			*components = append(*components, simComponent{omega: e.Omega, tv: e.Tv, up: true})
+		}
+	case "series", "parallel", "k_of_n":
+		if b.Type == "k_of_n" && (b.K < 1 || b.K > len(b.Blocks)) {
+			return nil, fmt.Errorf("k має бути від 1 до %d", len(b.Blocks))
+		}
+		if len(b.Blocks) == 0 {
+			return nil, fmt.Errorf("блок %q не містить складових", b.Type)
+		}
+		for _, child := range b.Blocks {
+			c, err := buildSimNode(child, catalog, components)
+			if err != nil {
+				return nil, err
+			}
+			node.children = append(node.children, c)
+		}
+	default:
+		return nil, fmt.Errorf("невідомий тип блоку %q", b.Type)
+	}
+	return node, nil
+}
+
+// Метод, що визначає, чи працює вузол схеми при поточних станах компонентів
+func (n *simNode) up(components []simComponent) bool {
+	if n.plannedOut {
+		return false
+	}
+	switch n.block.Type {
+	case "element":
+		for _, i := range n.components {
+			if !components[i].up {
+				return false
+			}
+		}
+		return true
+	case "series":
+		for _, c := range n.children {
+			if !c.up(components) {
+				return false
+			}
+		}
+		return true
+	case "parallel":
+		for _, c := range n.children {
+			if c.up(components) {
+				return true
+			}
+		}
+		return false
+	default: // k_of_n
+		upCount := 0
+		for _, c := range n.children {
+			if c.up(components) {
+				upCount++
+			}
+		}
+		return upCount >= n.block.K
+	}
+}
+
+// Метод, що збирає усі вузли з плановими простоями
+func (n *simNode) plannedNodes(out []*simNode) []*simNode {
+	if n.block.PlannedHours > 0 {
+		out = append(out, n)
+	}
+	for _, c := range n.children {
+		out = c.plannedNodes(out)
+	}
+	return out
+}
+
+// Статистична оцінка величини з 95% довірчим інтервалом
+type simEstimate struct {
+	Mean float64 `json:"mean"`
+	Low  float64 `json:"low"`
+	High float64 `json:"high"`
+}
+
+// Метод, що розраховує середнє значення вибірки та 95% довірчий інтервал для нього
+func newSimEstimate(values []float64) simEstimate {
+	n := float64(len(values))
+	if n == 0 {
+		return simEstimate{}
+	}
+	var sum, sumSq float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / n
+	for _, v := range values {
+		sumSq += (v - mean) * (v - mean)
+	}
+	var half float64
+	if n > 1 {
+		half = 1.96 * math.Sqrt(sumSq/(n-1)) / math.Sqrt(n)
+	}
+	return simEstimate{Mean: mean, Low: mean - half, High: mean + half}
+}
+
+// Результати моделювання методом Монте-Карло
+type simulationResult struct {
+	Years       int         `json:"years"`
+	Seed        int64       `json:"seed"`
+	Outages     int         `json:"outages"`      // загальна кількість відмов схеми
+	Frequency   simEstimate `json:"frequency"`    // частота відмов, рік^-1
+	Duration    simEstimate `json:"duration"`     // тривалість відмови, год
+	Downtime    simEstimate `json:"downtime"`     // сумарний простій за рік, год
+	Unavailable float64     `json:"unavailable"`  // коефіцієнт простою
+	DurationP50 float64     `json:"duration_p50"` // медіана тривалості відмови, год
+	DurationP90 float64     `json:"duration_p90"`
+	DurationP99 float64     `json:"duration_p99"`
+	// Розподіл кількості відмов за рік: частка років з 0, 1, 2, 3 та 4+ відмовами
+	YearsByOutages []float64 `json:"years_by_outages"`
+}
+
+// Метод, що моделює роботу схеми протягом заданої кількості років
+// Час роботи до відмови та час відновлення кожного елемента розподілені експоненційно
+func simulateScheme(b schemeBlock, catalog map[string]reliabilityElement, years int, seed int64) (simulationResult, error) {
+	if years < minSimulationYears || years > maxSimulationYears {
+		return simulationResult{}, fmt.Errorf("кількість років моделювання має бути від %d до %d", minSimulationYears, maxSimulationYears)
+	}
+	if count := countSchemeComponents(b); count*float64(years) > maxSimulationComponentYears {
+		return simulationResult{}, fmt.Errorf("схема з %g елементів занадто велика для моделювання протягом %d років (добуток не більше %d)", count, years, maxSimulationComponentYears)
+	}
+	var components []simComponent
+	root, err := buildSimNode(b, catalog, &components)
+	if err != nil {
+		return simulationResult{}, err
+	}
+	planned := root.plannedNodes(nil)
+
+	rng := rand.New(rand.NewSource(seed))
+	for i := range components {
+		components[i].nextChange = rng.ExpFloat64() * 8760 / components[i].omega
+	}
+
+	horizon := float64(years) * 8760
+	outagesPerYear := make([]float64, years)
+	downtimePerYear := make([]float64, years)
+	var durations []float64
+
+	// Час наступної зміни стану планового простою вузла
+	plannedNext := func(n *simNode, t float64) float64 {
+		year := math.Floor(t / 8760)
+		start := year*8760 + n.block.PlannedStart
+		end := start + n.block.PlannedHours
+		switch {
+		case t < start:
+			return start
+		case t < end:
+			return end
+		default:
+			return start + 8760
+		}
+	}
+	plannedAt := func(n *simNode, t float64) bool {
+		offset := math.Mod(t, 8760) - n.block.PlannedStart
+		if offset < 0 {
+			offset += 8760
+		}
+		return offset < n.block.PlannedHours
+	}
+
+	// Додає простій схеми [start, end) до статистики (простій, що переходить через межу року, ділиться між роками)
+	recordDowntime := func(start, end float64) {
+		for start < end {
+			year := int(start / 8760)
+			yearEnd := float64(year+1) * 8760
+			part := math.Min(end, yearEnd) - start
+			downtimePerYear[year] += part
+			start += part
+		}
+	}
+
+	t := 0.0
+	for _, n := range planned {
+		n.plannedOut = plannedAt(n, t)
+	}
+	systemUp := root.up(components)
+	downSince := 0.0
+	if !systemUp {
+		outagesPerYear[0]++
+	}
+
+	for t < horizon {
+		// Шукаємо найближчу подію: зміну стану компонента або планового простою
+		next := horizon
+		for i := range components {
+			next = math.Min(next, components[i].nextChange)
+		}
+		for _, n := range planned {
+			next = math.Min(next, plannedNext(n, t))
+		}
+		t = next
+		if t >= horizon {
+			break
+		}
+
+		for i := range components {
+			c := &components[i]
+			if c.nextChange == t {
+				c.up = !c.up
+				if c.up {
+					c.nextChange = t + rng.ExpFloat64()*8760/c.omega
+				} else {
+					c.nextChange = t + rng.ExpFloat64()*c.tv
+				}
+			}
+		}
+		for _, n := range planned {
+			n.plannedOut = plannedAt(n, t)
+		}
+
+		up := root.up(components)
+		if up == systemUp {
+			continue
+		}
+		systemUp = up
+		if !up {
+			downSince = t
+			outagesPerYear[int(t/8760)]++
+		} else {
+			durations = append(durations, t-downSince)
+			recordDowntime(downSince, t)
+		}
+	}
+	if !systemUp {
+		durations = append(durations, horizon-downSince)
+		recordDowntime(downSince, horizon)
+	}
+
+	res := simulationResult{Years: years, Seed: seed}
+	res.Frequency = newSimEstimate(outagesPerYear)
+	res.Downtime = newSimEstimate(downtimePerYear)
+	res.Duration = newSimEstimate(durations)
+	res.Outages = len(durations)
+	res.Unavailable = res.Downtime.Mean / 8760
+
+	sort.Float64s(durations)
+	percentile := func(p float64) float64 {
+		if len(durations) == 0 {
+			return 0
+		}
+		return durations[int(p*float64(len(durations)-1))]
+	}
+	res.DurationP50 = percentile(0.5)
+	res.DurationP90 = percentile(0.9)
+	res.DurationP99 = percentile(0.99)
+
+	res.YearsByOutages = make([]float64, 5)
+	for _, count := range outagesPerYear {
+		res.YearsByOutages[int(math.Min(count, 4))]++
+	}
+	for i := range res.YearsByOutages {
+		res.YearsByOutages[i] /= float64(years)
+	}
+	return res, nil
+}
+
+// Метод, що отримує параметри моделювання методом Монте-Карло з форми або параметрів запиту
+func getSimulationParams(r *http.Request) (int, int64, error) {
+	years, err1 := strconv.Atoi(r.FormValue("mc_years"))
+	seed, err2 := strconv.ParseInt(r.FormValue("mc_seed"), 10, 64)
+	if err1 != nil || err2 != nil {
+		return 0, 0, fmt.Errorf("кількість років та зерно генератора мають бути цілими числами")
+	}
+	return years, seed, nil
+}
+
+// Метод, що читає приклад структурної схеми (двоколова система з секційним вимикачем)
+func getSchemeExample() (string, error) {
+	content, err := os.ReadFile("./instance/prac_5_scheme_example.json")
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// Шлях, що обробляє розрахунок надійності довільної структурної схеми
+// Приймає схему у вигляді JSON (поле форми scheme або тіло запиту з Content-Type: application/json)
+func prac5Scheme(w http.ResponseWriter, r *http.Request) {
+	example, err := getSchemeExample()
+	if err != nil {
+		http.Error(w, "Failed to load scheme example: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	data := PageData{
+		IsIndex: false,
+		DefaultValues: map[string]interface{}{
+			"scheme":      example,
+			"monte_carlo": false,
+			"mc_years":    10000,
+			"mc_seed":     1,
+		},
+	}
+
+	if r.Method == http.MethodPost {
+		catalog, err := getPrac5Data()
+		if err != nil {
+			data.Error = "Error reading data file"
+			respond(w, r, "prac_5_scheme", data, "templates/prac_5_scheme.html", "templates/prac_5_simulation.html")
+			return
+		}
+
+		var scheme schemeBlock
+		var blocks []blockReliability
+
+		// Для API запитів схема передається безпосередньо у тілі запиту
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+			if err := json.NewDecoder(r.Body).Decode(&scheme); err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Bad JSON: " + err.Error()})
+				return
+			}
+			if _, _, err := solveScheme(scheme, catalog, 0, &blocks); err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+				return
+			}
+			response := map[string]interface{}{"scheme": blocks[0], "blocks": blocks}
+
+			// Моделювання методом Монте-Карло вмикається параметрами ?mc_years=&mc_seed=
+			if r.URL.Query().Get("mc_years") != "" {
+				years, seed, err := getSimulationParams(r)
+				if err == nil {
+					response["simulation"], err = simulateScheme(scheme, catalog, years, seed)
+				}
+				if err != nil {
+					writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+					return
+				}
+			}
+			writeJSON(w, http.StatusOK, response)
+			return
+		}
+
+		schemeStr := r.FormValue("scheme")
+		data.DefaultValues["scheme"] = schemeStr
+		if err := json.Unmarshal([]byte(schemeStr), &scheme); err != nil {
+			data.Error = "Bad scheme JSON: " + err.Error()
+			respond(w, r, "prac_5_scheme", data, "templates/prac_5_scheme.html", "templates/prac_5_simulation.html")
+			return
+		}
+		if _, _, err := solveScheme(scheme, catalog, 0, &blocks); err != nil {
+			data.Error = "Scheme error: " + err.Error()
+			respond(w, r, "prac_5_scheme", data, "templates/prac_5_scheme.html", "templates/prac_5_simulation.html")
+			return
+		}
+
+		data.Results = map[string]interface{}{
+			"scheme": blocks[0],
+			"blocks": blocks,
+		}
+
+		// Якщо користувач попросив, перевіряємо аналітичний результат моделюванням
+		if r.FormValue("monte_carlo") != "" {
+			data.DefaultValues["monte_carlo"] = true
+			data.DefaultValues["mc_years"] = r.FormValue("mc_years")
+			data.DefaultValues["mc_seed"] = r.FormValue("mc_seed")
+			years, seed, err := getSimulationParams(r)
+			var simulation simulationResult
+			if err == nil {
+				simulation, err = simulateScheme(scheme, catalog, years, seed)
+			}
+			if err != nil {
+				data.Error = "Simulation error: " + err.Error()
+				respond(w, r, "prac_5_scheme", data, "templates/prac_5_scheme.html", "templates/prac_5_simulation.html")
+				return
+			}
+			data.Results["simulation"] = simulation
+		}
+	}
+
+	respond(w, r, "prac_5_scheme", data, "templates/prac_5_scheme.html", "templates/prac_5_simulation.html")
+}
+
+// Елемент каталогу, що використовується як секційний вимикач 10 кВ двоколової системи
+const sectionalBreakerName = "В-10 кВ (малооливний)"
+
+// Метод, що шукає секційний вимикач у каталозі елементів ЕПС
+func getSectionalBreaker(catalog map[string]reliabilityElement) (reliabilityElement, error) {
+	e, ok := catalog[sectionalBreakerName]
+	if !ok {
+		return reliabilityElement{}, fmt.Errorf("секційний вимикач %q відсутній у каталозі", sectionalBreakerName)
+	}
+	return e, nil
+}
+
+// Метод, що моделює одноколову систему (лише аварійні відмови) та двоколову систему
+// з плановими ремонтами кіл, рознесеними на пів року, і секційним вимикачем
+func simulateTwoCircuitSystem(circuit []schemeBlock, catalog map[string]reliabilityElement, plannedHours float64, years int, seed int64) (simulationResult, simulationResult, error) {
+	single, err := simulateScheme(schemeBlock{Type: "series", Blocks: circuit}, catalog, years, seed)
+	if err != nil {
+		return simulationResult{}, simulationResult{}, err
+	}
+
+	if _, err := getSectionalBreaker(catalog); err != nil {
+		return simulationResult{}, simulationResult{}, err
+	}
+	double, err := simulateScheme(schemeBlock{Type: "series", Blocks: []schemeBlock{
+		{Type: "parallel", Blocks: []schemeBlock{
+			{Type: "series", Blocks: circuit, PlannedHours: plannedHours},
+			{Type: "series", Blocks: circuit, PlannedHours: plannedHours, PlannedStart: 4380},
+		}},
+		{Type: "element", Element: sectionalBreakerName},
+	}}, catalog, years, seed)
+	if err != nil {
+		return simulationResult{}, simulationResult{}, err
+	}
+	return single, double, nil
+}
+
+// Вхідні дані для економічного порівняння одноколової та двоколової схем
+type circuitEconomicsInput struct {
+	CapitalCost float64 // капіталовкладення в друге коло та секційний вимикач, грн
+	OMPct       float64 // щорічні витрати на обслуговування, % від капіталовкладень
+	Horizon     int     // горизонт розрахунку, років
+	DiscountPct float64 // ставка дисконтування, %
+}
+
+// Метод, що визначає частоту відмов (рік^-1) та середню тривалість відновлення (рік) двоколової системи
+// з двох однакових кіл з показниками w, tv (рік) та kp і секційного вимикача з каталогу.
+// Використано наближені формули для паралельного та послідовного з'єднань елементів
+// (R. Billinton, R. N. Allan, Reliability Evaluation of Power Systems, 2nd ed., розд. 7.2):
+// одночасний простій кіл ωдк = 2ω(ωtв + kп) триває tв·tв/(tв + tв) = tв/2,
+// а для послідовно ввімкненого вимикача tв.дс = (ωдк·tв/2 + ωсв·tв.св) / (ωдк + ωсв)
+func doubleCircuitReliability(w, tv, kp float64, breaker reliabilityElement) (float64, float64) {
+	wdk := 2 * w * (w*tv + kp)
+	wdc := wdk + breaker.Omega
+	if wdc == 0 {
+		return 0, 0
+	}
+	return wdc, (wdk*tv/2 + breaker.Omega*breaker.Tv/8760) / wdc
+}
+
+// Результати економічного порівняння схем
+type circuitEconomics struct {
+	SingleLoss float64 `json:"single_loss"` // щорічні збитки від перерв одноколової схеми, грн
+	DoubleLoss float64 `json:"double_loss"` // щорічні збитки від перерв двоколової схеми, грн
+	AnnualOM   float64 `json:"annual_om"`   // щорічні витрати на обслуговування другого кола, грн
+	Savings    float64 `json:"savings"`     // щорічна економія від зменшення збитків з урахуванням обслуговування, грн
+	PVSingle   float64 `json:"pv_single"`   // дисконтовані витрати одноколової схеми за горизонт, грн
+	PVDouble   float64 `json:"pv_double"`   // дисконтовані витрати двоколової схеми за горизонт, грн
+	NPV        float64 `json:"npv"`         // чистий дисконтований дохід від будівництва другого кола, грн
+	Payback    int     `json:"payback"`     // дисконтований строк окупності, років (0 - не окупається за горизонт)
+	Justified  bool    `json:"justified"`   // двоколова схема економічно доцільна
+}
+
+// Метод, що порівнює дисконтовані витрати одноколової та двоколової схем за горизонт розрахунку
+// Ma, Mp - збитки від аварійних та планових перерв одноколової схеми, MaDouble - від аварійних перерв двоколової, грн/рік
+func compareCircuitSchemes(Ma, Mp, MaDouble float64, in circuitEconomicsInput) circuitEconomics {
+	var res circuitEconomics
+	res.SingleLoss = Ma + Mp
+	// Планові ремонти кіл двоколової схеми не призводять до перерв електропостачання
+	res.DoubleLoss = MaDouble
+	res.AnnualOM = in.CapitalCost * in.OMPct / 100
+	res.Savings = res.SingleLoss - res.DoubleLoss - res.AnnualOM
+
+	rate := in.DiscountPct / 100
+	res.PVDouble = in.CapitalCost
+	cumulative := 0.0
+	for t := 1; t <= in.Horizon; t++ {
+		discount := math.Pow(1+rate, float64(t))
+		res.PVSingle += res.SingleLoss / discount
+		res.PVDouble += (res.DoubleLoss + res.AnnualOM) / discount
+		cumulative += res.Savings / discount
+		if res.Payback == 0 && cumulative >= in.CapitalCost {
+			res.Payback = t
+		}
+	}
+	res.NPV = res.PVSingle - res.PVDouble
+	res.Justified = res.NPV > 0
+
+	res.SingleLoss = round(res.SingleLoss, 0)
+	res.DoubleLoss = round(res.DoubleLoss, 0)
+	res.AnnualOM = round(res.AnnualOM, 0)
+	res.Savings = round(res.Savings, 0)
+	res.PVSingle = round(res.PVSingle, 0)
+	res.PVDouble = round(res.PVDouble, 0)
+	res.NPV = round(res.NPV, 0)
+	return res
+}
+
+// Рядок форми п'ятої практичної: елемент ЕПС та його кількість
+type prac5Row struct {
+	Element  string `json:"element"`
+	Quantity int    `json:"quantity"`
+	Raw      string `json:"raw_quantity,omitempty"` // введене значення кількості, якщо воно не є числом
+	Error    string `json:"error,omitempty"`
+}
+
+// Метод, що розбирає рядки форми та перевіряє кожен з них
+// Повертає рядки (для повторного виведення у формі) та список помилок з номерами рядків
+func parsePrac5Rows(elements, quantities []string, catalog map[string]reliabilityElement) ([]prac5Row, []string) {
+	var rows []prac5Row
+	var rowErrors []string
+	if len(elements) != len(quantities) {
+		rowErrors = append(rowErrors, fmt.Sprintf("кількість елементів (%d) не збігається з кількістю значень quantity (%d)", len(elements), len(quantities)))
+	}
+
+	n := len(elements)
+	if len(quantities) > n {
+		n = len(quantities)
+	}
+	if n == 0 {
+		rowErrors = append(rowErrors, "не задано жодного елемента")
+	}
+	for i := 0; i < n; i++ {
+		var row prac5Row
+		if i < len(elements) {
+			row.Element = elements[i]
+		}
+		var rawQuantity string
+		if i < len(quantities) {
+			rawQuantity = strings.TrimSpace(quantities[i])
+		}
+
+		q, err := strconv.Atoi(rawQuantity)
+		switch {
+		case row.Element == "":
+			row.Error = "елемент не обрано"
+		case catalog[row.Element].Name == "":
+			row.Error = fmt.Sprintf("елемент %q відсутній у каталозі", row.Element)
+		case err != nil:
+			row.Error = fmt.Sprintf("кількість %q не є цілим числом", rawQuantity)
+		case q < 1:
+			row.Error = "кількість має бути не менше 1"
+		}
+		if err != nil {
+			row.Raw = rawQuantity
+		}
+		row.Quantity = q
+		if row.Error != "" {
+			rowErrors = append(rowErrors, fmt.Sprintf("рядок %d: %s", i+1, row.Error))
+		}
+		rows = append(rows, row)
+	}
+	return rows, rowErrors
+}
+
+// Шлях, що обробляє п'яту практичну роботу
+func prac5Task1(w http.ResponseWriter, r *http.Request) {
+	defaultValues := map[string]interface{}{
+		"Zpera":       23.6,
+		"Zperp":       17.6,
+		"w":           0.01,
+		"tv":          0.045,
+		"Pm":          5120.0,
+		"Tm":          6451.0,
+		"kp":          0.004,
+		"derive":      false,
+		"monte_carlo": false,
+		"mc_years":    10000,
+		"mc_seed":     1,
+		"rows":        []prac5Row{},
+		// Економічне порівняння схем
+		"capital_cost": 15000000.0,
+		"om_pct":       2.0,
+		"horizon":      20,
+		"discount_pct": 10.0,
+	}
+	data := PageData{IsIndex: false, DefaultValues: defaultValues}
+
+	// Каталог елементів потрібен для списків вибору у вже заповнених рядках форми
+	pracData, err := getPrac5Data()
+	if err != nil {
+		data.Error = "Error reading data file"
+		respond(w, r, "prac_5_task_1", data, "templates/prac_5_task_1.html", "templates/prac_5_simulation.html")
+		return
+	}
+	catalogElements := make([]reliabilityElement, 0, len(pracData))
+	for _, e := range pracData {
+		catalogElements = append(catalogElements, e)
+	}
+	defaultValues["groups"] = groupPrac5Elements(catalogElements)
+
+	if r.Method == http.MethodPost {
+		// Отримання користувацього вводу
+		r.ParseForm()
+		quantitiesStr := r.Form["quantity[]"]
+		elements := r.Form["element[]"]
+		Zpera, err1 := getFloat(r, "Zpera")
+		Zperp, err2 := getFloat(r, "Zperp")
+		// Параметри для розрахунку збитків від перерв електропостачання (пункт 2)
+		wGtp, err3 := getFloat(r, "w")
+		tvGtp, err4 := getFloat(r, "tv")
+		Pm, err5 := getFloat(r, "Pm")
+		Tm, err6 := getFloat(r, "Tm")
+		kp, err7 := getFloat(r, "kp")
+		derive := r.FormValue("derive") != ""
+		capitalCost, err8 := getFloat(r, "capital_cost")
+		omPct, err9 := getFloat(r, "om_pct")
+		horizon, err10 := strconv.Atoi(r.FormValue("horizon"))
+		discountPct, err11 := getFloat(r, "discount_pct")
+
+		defaultValues["Zpera"] = Zpera
+		defaultValues["Zperp"] = Zperp
+		defaultValues["w"] = wGtp
+		defaultValues["tv"] = tvGtp
+		defaultValues["Pm"] = Pm
+		defaultValues["Tm"] = Tm
+		defaultValues["kp"] = kp
+		defaultValues["derive"] = derive
+		defaultValues["capital_cost"] = capitalCost
+		defaultValues["om_pct"] = omPct
+		defaultValues["horizon"] = horizon
+		defaultValues["discount_pct"] = discountPct
+
+		// Перевіряємо кожен рядок окремо і повертаємо користувачу введений список елементів
+		rows, rowErrors := parsePrac5Rows(elements, quantitiesStr, pracData)
+		defaultValues["rows"] = rows
+
+		if err1 != nil || err2 != nil || err3 != nil || err4 != nil || err5 != nil || err6 != nil || err7 != nil ||
+			Zpera < 0 || Zperp < 0 || wGtp < 0 || tvGtp < 0 || Pm < 0 || Tm < 0 || Tm > 8760 || kp < 0 || kp > 1 ||
+			err8 != nil || err9 != nil || err10 != nil || err11 != nil || capitalCost < 0 || omPct < 0 ||
+			horizon < 1 || horizon > 100 || discountPct < 0 {
+			data.Error = "Bad values: check inputs"
+			respond(w, r, "prac_5_task_1", data, "templates/prac_5_task_1.html", "templates/prac_5_simulation.html")
+			return
+		}
+
+		if len(rowErrors) > 0 {
+			data.Error = "Bad elements: " + strings.Join(rowErrors, "; ")
+			respond(w, r, "prac_5_task_1", data, "templates/prac_5_task_1.html", "templates/prac_5_simulation.html")
+			return
+		}
+
+		breaker, errB := getSectionalBreaker(pracData)
+		if errB != nil {
+			data.Error = "Bad elements: " + errB.Error()
+			respond(w, r, "prac_5_task_1", data, "templates/prac_5_task_1.html", "templates/prac_5_simulation.html")
+			return
+		}
+
+		var woc_sum, tvoc_num, max_t_plan float64
+		// Елементи одного кола для моделювання методом Монте-Карло
+		var circuit []schemeBlock
+
+		for _, row := range rows {
+			props := pracData[row.Element]
+			quantity := float64(row.Quantity)
+
+			woc_sum += quantity * props.Omega
+			tvoc_num += quantity * props.Omega * props.Tv
+
+			if props.Tp > max_t_plan {
+				max_t_plan = props.Tp
+			}
+			circuit = append(circuit, schemeBlock{Type: "element", Element: row.Element, Count: row.Quantity})
+		}
+
+		// Внесок кожного рядка в частоту відмов одноколової системи
+		contributions := make([]map[string]interface{}, 0, len(rows))
+		for _, row := range rows {
+			woc_i := float64(row.Quantity) * pracData[row.Element].Omega
+			var share float64
+			if woc_sum > 0 {
+				share = woc_i / woc_sum * 100
+			}
+			contributions = append(contributions, map[string]interface{}{
+				"element":  row.Element,
+				"quantity": row.Quantity,
+				"omega":    pracData[row.Element].Omega,
+				"woc":      round(woc_i, 4),
+				"share":    round(share, 2),
+			})
+		}
+
+		// Розрахунки
+		woc := woc_sum
+		var tvoc float64
+		if woc > 0 {
+			tvoc = tvoc_num / woc
+		}
+
+		// Коефіцієнт аварійного простою одноколової системи
+		kaoc := (woc * tvoc) / 8760
+		// Коефіцієнт планового простою одноколової системи
+		kpoc := (1.2 * max_t_plan) / 8760
+		// Частота відмов двоколової системи з урахуванням секційного вимикача
+		// та середня тривалість її відновлення, рік
+		wdc, tvdc := doubleCircuitReliability(woc, tvoc/8760, kpoc, breaker)
+		// Коефіцієнт надійності
+		var koef float64
+		if wdc > 0 {
+			koef = woc / wdc
+		}
+		// Коефіцієнт аварійного простою двоколової системи
+		kadc := wdc * tvdc
+
+		// Перевірка аналітичних формул моделюванням методом Монте-Карло
+		var simulation map[string]interface{}
+		if r.FormValue("monte_carlo") != "" {
+			defaultValues["monte_carlo"] = true
+			defaultValues["mc_years"] = r.FormValue("mc_years")
+			defaultValues["mc_seed"] = r.FormValue("mc_seed")
+			years, seed, err := getSimulationParams(r)
+			if err == nil && len(circuit) == 0 {
+				err = fmt.Errorf("схема не містить жодного елемента")
+			}
+			var single, double simulationResult
+			if err == nil {
+				single, double, err = simulateTwoCircuitSystem(circuit, pracData, 1.2*max_t_plan, years, seed)
+			}
+			if err != nil {
+				data.Error = "Simulation error: " + err.Error()
+				respond(w, r, "prac_5_task_1", data, "templates/prac_5_task_1.html", "templates/prac_5_simulation.html")
+				return
+			}
+			simulation = map[string]interface{}{"mc_single": single, "mc_double": double}
+		}
+
+		// Пункт 2
+		// За бажанням користувача показники надійності ГТП беруться з одноколової системи пункту 1
+		if derive {
+			wGtp = woc
+			tvGtp = tvoc / 8760
+			kp = kpoc
+		}
 
-		M_1 := w * tv * Pm * Tm
+		// Математичне сподівання аварійного та планового недовідпущення електроенергії
+		M_1 := wGtp * tvGtp * Pm * Tm
 		M_2 := kp * Pm * Tm
 		M := Zpera*M_1 + Zperp*M_2
 
+		// Пункт 3. Економічне порівняння одноколової та двоколової схем
+		// Збитки одноколової схеми - це M з пункту 2, збитки двоколової визначаються за тією ж формулою
+		// з частотою відмов та тривалістю відновлення двоколової системи з двох кіл з показниками ГТП
+		wdcGtp, tvdcGtp := doubleCircuitReliability(wGtp, tvGtp, kp, breaker)
+		M_dc := wdcGtp * tvdcGtp * Pm * Tm
+		economics := compareCircuitSchemes(Zpera*M_1, Zperp*M_2, Zpera*M_dc, circuitEconomicsInput{
+			CapitalCost: capitalCost,
+			OMPct:       omPct,
+			Horizon:     horizon,
+			DiscountPct: discountPct,
+		})
+
 		data.Results = map[string]interface{}{
 			"woc":  round(woc, 4),
 			"wdc":  round(wdc, 4),
 			"koef": koef,
 			"M":    round(M, 0),
+			"w":    round(wGtp, 4),
+			"tv":   round(tvGtp, 6),
+			"kp":   round(kp, 6),
+			"Wa":   round(M_1, 0),
+			"Wp":   round(M_2, 0),
+			"Ma":   round(Zpera*M_1, 0),
+			"Mp":   round(Zperp*M_2, 0),
+
+			"contributions": contributions,
+			"breaker":       breaker.Name,
+			"kaoc":          round(kaoc, 6),
+			"kpoc":          round(kpoc, 6),
+			"kadc":          round(kadc, 8),
+			"w_dc":          round(wdcGtp, 4),
+			"tv_dc":         round(tvdcGtp, 6),
+			"Wa_dc":         round(M_dc, 0),
+			"economics":     economics,
+		}
+		for key, value := range simulation {
+			data.Results[key] = value
 		}
 	}
 
-	render(w, "prac_5_task_1", data, "templates/prac_5_task_1.html")
+	respond(w, r, "prac_5_task_1", data, "templates/prac_5_task_1.html", "templates/prac_5_simulation.html")
 }
 
-// Метод, щоб знайти найближчі межі до числа у списку(Використовуємо при пошуці Кв)
-func findNearestNeighbors(lst []int, target int) (int, int) {
-	sort.Ints(lst)
-	lower := -1
-	higher := -1
-	for _, v := range lst {
-		if v < target {
-			lower = v
-		} else if v > target {
-			higher = v
-			break
-		}
-	}
-	return lower, higher
+// Способи пошуку Кр у таблицях: snap - як у ручних розрахунках, Кв заокруглюється до найближчого меншого
+// стовпця таблиці; interpolate - білінійна інтерполяція за ne та Кв
+const (
+	kpModeSnap        = "snap"
+	kpModeInterpolate = "interpolate"
+)
+
+// Клітинка таблиці коефіцієнтів Кр, використана при пошуку, та її вага в результаті
+type kpCell struct {
+	Ne     string  `json:"ne"`
+	Kv     string  `json:"kv"`
+	Value  float64 `json:"value"`
+	Weight float64 `json:"weight"`
 }
 
-// Метод для пошуку значення розрахункових коефіцієнтів Кр
-// для мереж живлення напругою до 1000 В (Т0 = 10 хв.)
-func getKp1(ne int, groupUseCoff float64) (float64, error) {
-	file, err := os.Open("./instance/prac_6_data_1.json")
+// Рядок таблиці коефіцієнтів Кр, що відповідає значенням ne від Min до Max включно
+type kpTableRow struct {
+	Label  string
+	Min    int
+	Max    int
+	Values map[string]float64
+}
+
+// Метод, що зчитує таблицю коефіцієнтів Кр. Ключі рядків мають вигляд «15» або діапазону «10;25»,
+// ключі стовпців - значення Кв («0.1», «0.15» тощо)
+func loadKpTable(path string) ([]kpTableRow, []float64, error) {
+	file, err := os.Open(path)
 	if err != nil {
-		return 0, err
+		return nil, nil, err
 	}
 	defer file.Close()
 
 	var data map[string]map[string]float64
 	if err := json.NewDecoder(file).Decode(&data); err != nil {
-		return 0, err
+		return nil, nil, err
+	}
+
+	var rows []kpTableRow
+	coeffSet := make(map[float64]bool)
+	for k, values := range data {
+		parts := strings.Split(k, ";")
+		minV, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return nil, nil, fmt.Errorf("bad row key %q in %s", k, path)
+		}
+		maxV := minV
+		label := k
+		if len(parts) == 2 {
+			// Верхня межа останнього діапазону може не вміщатися в int на 32-бітних системах
+			maxV64, err := strconv.ParseInt(parts[1], 10, 64)
+			if err != nil {
+				return nil, nil, fmt.Errorf("bad row key %q in %s", k, path)
+			}
+			maxV = int(math.Min(float64(maxV64), math.MaxInt32))
+			// Останній діапазон таблиці 3.4 не обмежений зверху
+			if maxV == math.MaxInt32 {
+				label = fmt.Sprintf("≥%d", minV)
+			} else {
+				label = fmt.Sprintf("%d–%d", minV, maxV)
+			}
+		}
+		rows = append(rows, kpTableRow{Label: label, Min: minV, Max: maxV, Values: values})
+		for c := range values {
+			f, err := strconv.ParseFloat(c, 64)
+			if err != nil {
+				return nil, nil, fmt.Errorf("bad column key %q in %s", c, path)
+			}
+			coeffSet[f] = true
+		}
 	}
+	// Діапазони сусідніх рядків можуть перетинатися на межі («9;10» та «10;25»),
+	// тому сортуємо рядки, щоб завжди обирати перший з них
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Min < rows[j].Min })
 
-	// Визначити найближчий коефіцієнтний ключ.
-    // Ключі мають вигляд «0,1», «0,15» тощо.
-    // Потрібно знайти максимальний ключ <= groupUseCoff.
-    // Припустимо, що рядок «1» існує, щоб отримати ключі.
-	firstRow := data["1"]
 	var coeffs []float64
-	for k := range firstRow {
-		f, _ := strconv.ParseFloat(k, 64)
-		coeffs = append(coeffs, f)
+	for c := range coeffSet {
+		coeffs = append(coeffs, c)
 	}
 	sort.Float64s(coeffs)
+	if len(rows) == 0 || len(coeffs) == 0 {
+		return nil, nil, fmt.Errorf("empty Kp table %s", path)
+	}
+	return rows, coeffs, nil
+}
 
-	closestCoeff := coeffs[0]
-	for _, c := range coeffs {
-		if c <= groupUseCoff {
-			closestCoeff = c
-		} else {
+// Метод, що знаходить Кр у таблиці за ефективною кількістю ЕП та груповим коефіцієнтом використання.
+// Якщо рядка для ne немає, значення інтерполюється між сусідніми рядками. Повертає також клітинки
+// таблиці, з яких отримано результат, щоб його можна було перевірити вручну
+func lookupKp(path string, ne int, groupUseCoff float64, mode string) (float64, []kpCell, error) {
+	rows, coeffs, err := loadKpTable(path)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	// Визначаємо рядки таблиці (за ne) та їх ваги
+	type weighted struct {
+		row    kpTableRow
+		weight float64
+	}
+	var neRows []weighted
+	for _, row := range rows {
+		if ne >= row.Min && ne <= row.Max {
+			neRows = []weighted{{row, 1}}
 			break
 		}
 	}
-	coeffKey := fmt.Sprintf("%g", closestCoeff)
+	if neRows == nil {
+		var lower, higher *kpTableRow
+		for i := range rows {
+			if rows[i].Max < ne {
+				lower = &rows[i]
+			} else if rows[i].Min > ne && higher == nil {
+				higher = &rows[i]
+			}
+		}
+		if lower == nil || higher == nil {
+			return 0, nil, fmt.Errorf("ne=%d is out of table range", ne)
+		}
+		t := float64(ne-lower.Max) / float64(higher.Min-lower.Max)
+		neRows = []weighted{{*lower, 1 - t}, {*higher, t}}
+	}
+
+	// Значення Кв поза межами таблиці не можна ні заокруглити, ні інтерполювати
+	if groupUseCoff < coeffs[0] || groupUseCoff > coeffs[len(coeffs)-1] {
+		return 0, nil, fmt.Errorf("Kv=%.2f is out of table range [%g; %g]", groupUseCoff, coeffs[0], coeffs[len(coeffs)-1])
+	}
 
-	if row, ok := data[strconv.Itoa(ne)]; ok {
-		if val, ok := row[coeffKey]; ok {
-			return val, nil
+	// Визначаємо стовпці таблиці (за Кв) та їх ваги
+	type column struct {
+		coeff  float64
+		weight float64
+	}
+	var kvColumns []column
+	if mode == kpModeInterpolate {
+		i := sort.SearchFloat64s(coeffs, groupUseCoff)
+		if coeffs[i] == groupUseCoff {
+			kvColumns = []column{{coeffs[i], 1}}
+		} else {
+			t := (groupUseCoff - coeffs[i-1]) / (coeffs[i] - coeffs[i-1])
+			kvColumns = []column{{coeffs[i-1], 1 - t}, {coeffs[i], t}}
+		}
+	} else {
+		// Потрібно знайти максимальний стовпець <= groupUseCoff
+		var closestCoeff float64
+		for _, c := range coeffs {
+			if c <= groupUseCoff {
+				closestCoeff = c
+			} else {
+				break
+			}
 		}
+		kvColumns = []column{{closestCoeff, 1}}
 	}
 
-	// Інтерполяція
-	var intKeys []int
-	for k := range data {
-		i, _ := strconv.Atoi(k)
-		intKeys = append(intKeys, i)
+	value := 0.0
+	var cells []kpCell
+	for _, r := range neRows {
+		for _, c := range kvColumns {
+			coeffKey := fmt.Sprintf("%g", c.coeff)
+			v, ok := r.row.Values[coeffKey]
+			if !ok {
+				return 0, nil, fmt.Errorf("Kp table has no value for ne=%s, Kv=%s", r.row.Label, coeffKey)
+			}
+			weight := r.weight * c.weight
+			value += weight * v
+			cells = append(cells, kpCell{Ne: r.row.Label, Kv: coeffKey, Value: v, Weight: round(weight, 4)})
+		}
 	}
-	lower, higher := findNearestNeighbors(intKeys, ne)
+	return value, cells, nil
+}
 
-	if lower != -1 && higher != -1 {
-		rowLower := data[strconv.Itoa(lower)]
-		rowHigher := data[strconv.Itoa(higher)]
+// Метод для пошуку значення розрахункових коефіцієнтів Кр
+// для мереж живлення напругою до 1000 В (Т0 = 10 хв.), таблиця 3.3
+func getKp1(ne int, groupUseCoff float64, mode string) (float64, []kpCell, error) {
+	return lookupKp("./instance/prac_6_data_1.json", ne, groupUseCoff, mode)
+}
+
+// Метод для пошуку значення розрахункових коефіцієнтів Кр
+// на рівні шин низької напруги цехової ТП (Т0 = 2,5 год.), таблиця 3.4
+func getKp2(ne int, groupUseCoff float64, mode string) (float64, []kpCell, error) {
+	return lookupKp("./instance/prac_6_data_2.json", ne, groupUseCoff, mode)
+}
+
+// Електроприймач (ЕП) з таблиці вихідних даних
+type electricReceiver struct {
+	Naming string
+	Nu     float64
+	Cos    float64
+	Uh     float64
+	N      float64
+	Ph     float64
+	KB     float64
+	Tg     float64
+	// Ознака того, що tg φ не задано і його розраховано з cos φ
+	TgDerived bool
+}
+
+// Розрахункові показники одного ЕП
+type receiverLoad struct {
+	NPh       float64
+	NPhKB     float64
+	NPhKBtg   float64
+	NPhSquare float64
+	Ip        float64
+}
+
+// Група ЕП (ШР, крупні ЕП або цех у цілому) із сумарними та розрахунковими навантаженнями
+type receiverGroup struct {
+	Name         string
+	Receivers    []electricReceiver
+	Loads        []receiverLoad
+	N            float64
+	NPh          float64
+	NPhKB        float64
+	NPhKBtg      float64
+	NPhSquare    float64
+	GroupUseCoff float64
+	Ne           int
+	Kp           float64
+	KpCells      []kpCell
+	Valid        bool
+	Pp           float64
+	Qp           float64
+	Sp           float64
+	Ip           float64
+}
+
+// Метод, що знаходить tg φ, який відповідає cos φ ЕП
+func (e electricReceiver) tgFromCos() float64 {
+	return math.Sqrt(1-math.Pow(e.Cos, 2)) / e.Cos
+}
 
-		valLower := rowLower[coeffKey]
-		valHigher := rowHigher[coeffKey]
+// Метод, що перевіряє, чи відповідає заданий tg φ значенню cos φ (з урахуванням заокруглення довідкових даних)
+func (e electricReceiver) tgMatchesCos() bool {
+	expected := e.tgFromCos()
+	return e.TgDerived || math.Abs(e.Tg-expected) <= 0.02+0.05*expected
+}
 
-		slope := (valHigher - valLower) / float64(higher - lower)
-		value := valLower + slope*float64(ne-lower)
-		return value, nil
+// Метод, що знаходить показники ЕП: n⋅Pн, n⋅Pн⋅Кв, n⋅Pн⋅Кв⋅tg φ, n⋅Pн² та розрахунковий струм
+func (e electricReceiver) load() receiverLoad {
+	nPh := e.N * e.Ph
+	nPhKB := nPh * e.KB
+	return receiverLoad{
+		NPh:       nPh,
+		NPhKB:     nPhKB,
+		NPhKBtg:   nPhKB * e.Tg,
+		NPhSquare: e.N * math.Pow(e.Ph, 2),
+		Ip:        nPh / (math.Sqrt(3) * e.Uh * e.Cos * e.Nu),
 	}
+}
 
-	return 0, fmt.Errorf("Kp1 lookup failed for ne=%d", ne)
+// Метод, що створює групу ЕП та підсумовує показники її електроприймачів
+func newReceiverGroup(name string, receivers []electricReceiver) receiverGroup {
+	g := receiverGroup{Name: name, Receivers: receivers}
+	for _, e := range receivers {
+		l := e.load()
+		g.Loads = append(g.Loads, l)
+		g.N += e.N
+		g.NPh += l.NPh
+		g.NPhKB += l.NPhKB
+		g.NPhKBtg += l.NPhKBtg
+		g.NPhSquare += l.NPhSquare
+	}
+	// Знаходимо груповий коефіцієнт використання
+	if g.NPh > 0 {
+		g.GroupUseCoff = g.NPhKB / g.NPh
+	}
+	return g
 }
 
-func getKp2(ne int, groupUseCoff float64) (float64, error) {
-	file, err := os.Open("./instance/prac_6_data_2.json")
-	if err != nil {
-		return 0, err
+// Метод, що знаходить розрахункові навантаження групи за відомим коефіцієнтом Кр
+func (g *receiverGroup) applyKp(kp float64) {
+	g.Valid = true
+	g.Kp = kp
+	g.Pp = kp * g.NPhKB
+	g.Qp = kp * g.NPhKBtg
+	g.Sp = math.Sqrt(math.Pow(g.Pp, 2) + math.Pow(g.Qp, 2))
+
+	// Середня напруга для розрахунку групового струму
+	meanUh := 0.38
+	if len(g.Receivers) > 0 {
+		var sumUh float64
+		for _, e := range g.Receivers {
+			sumUh += e.Uh
+		}
+		meanUh = sumUh / float64(len(g.Receivers))
 	}
-	defer file.Close()
+	g.Ip = g.Pp / meanUh
+}
 
-	var data map[string]map[string]float64
-	if err := json.NewDecoder(file).Decode(&data); err != nil {
-		return 0, err
+// Метод, що розраховує навантаження ШР, використовуючи таблицю 3.3 (мережі до 1000 В, Т0 = 10 хв.)
+// Якщо Кр не знайдено, розрахункові навантаження не визначаються, а група позначається недійсною
+func calcCabinetLoad(name string, receivers []electricReceiver, kpMode string) (receiverGroup, error) {
+	g := newReceiverGroup(name, receivers)
+	// Знаходимо ефективну кількість ЕП
+	if g.NPhSquare > 0 {
+		g.Ne = int(math.Ceil(math.Pow(g.NPh, 2) / g.NPhSquare))
+	}
+	kp, cells, err := getKp1(g.Ne, g.GroupUseCoff, kpMode)
+	if err != nil {
+		return g, err
 	}
+	g.KpCells = cells
+	g.applyKp(kp)
+	return g, nil
+}
 
-	var targetRow map[string]float64
-	for k, row := range data {
-		parts := strings.Split(k, ";")
-		if len(parts) == 2 {
-			minV, _ := strconv.Atoi(parts[0])
-			maxV, _ := strconv.ParseInt(parts[1], 10, 64)
-			if ne >= minV && int64(ne) <= maxV {
-				targetRow = row
-				break
+// Метод, що розраховує навантаження на шинах 0,38 кВ ТП за сумами по всіх ЕП цеху (auto),
+// використовуючи таблицю 3.4. Якщо задано manual, замість сум по ЕП використовуються введені вручну
+// n, n⋅Pн, n⋅Pн⋅Кв, n⋅Pн⋅Кв⋅tg φ та n⋅Pн², а про розбіжності з сумами по ЕП повертаються попередження
+func calcShopLoad(auto receiverGroup, manual *receiverGroup, kpMode string) (receiverGroup, []string, error) {
+	g := auto
+
+	var warnings []string
+	if manual != nil {
+		totals := []struct {
+			name         string
+			auto, manual float64
+		}{
+			{"n", g.N, manual.N},
+			{"n⋅Pн", g.NPh, manual.NPh},
+			{"n⋅Pн⋅Кв", g.NPhKB, manual.NPhKB},
+			{"n⋅Pн⋅Кв⋅tg φ", g.NPhKBtg, manual.NPhKBtg},
+			{"n⋅Pн²", g.NPhSquare, manual.NPhSquare},
+		}
+		for _, t := range totals {
+			// Допускаємо розбіжність через заокруглення введених значень
+			if math.Abs(t.manual-t.auto) > math.Max(0.005*math.Abs(t.auto), 0.5) {
+				warnings = append(warnings, fmt.Sprintf("Введене значення %s = %g не збігається з сумою по ЕП (%g)",
+					t.name, t.manual, round(t.auto, 2)))
 			}
 		}
+		g.N = manual.N
+		g.NPh = manual.NPh
+		g.NPhKB = manual.NPhKB
+		g.NPhKBtg = manual.NPhKBtg
+		g.NPhSquare = manual.NPhSquare
+		g.GroupUseCoff = 0
+		if g.NPh > 0 {
+			g.GroupUseCoff = g.NPhKB / g.NPh
+		}
 	}
 
-	if targetRow == nil {
-		return 0, fmt.Errorf("range not found for ne=%d", ne)
+	// Знаходимо ефективну кількість ЕП цеху в цілому
+	if g.NPhSquare > 0 {
+		g.Ne = int(math.Round(math.Pow(g.NPh, 2) / g.NPhSquare))
+	}
+	kp, cells, err := getKp2(g.Ne, g.GroupUseCoff, kpMode)
+	if err != nil {
+		return g, warnings, err
 	}
+	g.KpCells = cells
+	g.applyKp(kp)
+	return g, warnings, nil
+}
 
-	var coeffs []float64
-	for k := range targetRow {
-		f, _ := strconv.ParseFloat(k, 64)
-		coeffs = append(coeffs, f)
+// Метод, що формує результати розрахунку ШР для відображення у таблиці
+func (g receiverGroup) results() map[string]interface{} {
+	var nPh, ip, nPhKB, nPhKBtg, nPhSquare []float64
+	for _, l := range g.Loads {
+		nPh = append(nPh, round(l.NPh, 2))
+		ip = append(ip, round(l.Ip, 2))
+		nPhKB = append(nPhKB, round(l.NPhKB, 2))
+		nPhKBtg = append(nPhKBtg, round(l.NPhKBtg, 2))
+		nPhSquare = append(nPhSquare, round(l.NPhSquare, 2))
+	}
+	results := map[string]interface{}{
+		"name":     g.Name,
+		"nPh_list": nPh, "Ip_list": ip, "nPhKB_list": nPhKB, "nPhKBtg_list": nPhKBtg, "nPh_square_list": nPhSquare,
+		"group_use_coff": round(g.GroupUseCoff, 1), "ne": g.Ne,
+		"N": int(g.N), "nPh_sum": int(g.NPh), "nPhKB_sum": round(g.NPhKB, 2), "nPhKBtg_sum": round(g.NPhKBtg, 2),
+		"nPh_square_sum": round(g.NPhSquare, 2), "valid": g.Valid,
+	}
+	// Навантаження, що залежать від Кр, відображаються лише якщо Кр знайдено
+	if g.Valid {
+		results["Kp"] = round(g.Kp, 2)
+		results["Kp_cells"] = g.KpCells
+		results["Pp"] = round(g.Pp, 2)
+		results["Qp"] = round(g.Qp, 2)
+		results["Sp"] = round(g.Sp, 2)
+		results["Ip"] = round(g.Ip, 2)
+	}
+	return results
+}
+
+// Метод, що повертає вхідні дані групи ЕП у форматі таблиці значень по змовчуванню,
+// щоб після розрахунків значення, введені користувачем, лишились
+func (g receiverGroup) values() map[string]interface{} {
+	naming := []string{}
+	var nu, cos, uh, n, ph, kb, tg, tgCos []float64
+	var tgDerived, tgMismatch []bool
+	for _, e := range g.Receivers {
+		naming = append(naming, e.Naming)
+		nu = append(nu, e.Nu)
+		cos = append(cos, e.Cos)
+		uh = append(uh, e.Uh)
+		n = append(n, e.N)
+		ph = append(ph, e.Ph)
+		kb = append(kb, e.KB)
+		tg = append(tg, round(e.Tg, 2))
+		tgCos = append(tgCos, round(e.tgFromCos(), 2))
+		tgDerived = append(tgDerived, e.TgDerived)
+		tgMismatch = append(tgMismatch, !e.tgMatchesCos())
+	}
+	return map[string]interface{}{
+		"name": g.Name, "naming": naming,
+		"nu[]": nu, "cos[]": cos, "Uh[]": uh, "n[]": n, "Ph[]": ph, "KB[]": kb, "tg[]": tg,
+		"tg_cos[]": tgCos, "tg_derived[]": tgDerived, "tg_mismatch[]": tgMismatch,
+	}
+}
+
+// Метод, що зчитує з форми перелік ЕП. suffix визначає групу полів (наприклад, "_big" для крупних ЕП)
+func getReceiversFromForm(r *http.Request, suffix string) ([]electricReceiver, error) {
+	naming := r.Form["naming"+suffix+"[]"]
+	// tg φ може бути не задано (порожнє поле або «-»), тому зчитуємо його окремо
+	tg := r.Form["tg"+suffix+"[]"]
+
+	var columns [6][]float64
+	for k, key := range []string{"nu", "cos", "Uh", "n", "Ph", "KB"} {
+		list, err := getFloatList(r, key+suffix+"[]")
+		if err != nil {
+			return nil, err
+		}
+		columns[k] = list
+	}
+	nu, cos, Uh, n, Ph, KB := columns[0], columns[1], columns[2], columns[3], columns[4], columns[5]
+
+	count := len(nu)
+	for _, list := range [][]float64{cos, Uh, n, Ph, KB} {
+		if len(list) != count {
+			return nil, errors.New("receiver columns have different lengths")
+		}
+	}
+	if len(tg) > count {
+		return nil, errors.New("receiver columns have different lengths")
 	}
-	sort.Float64s(coeffs)
 
-	closestCoeff := coeffs[0]
-	for _, c := range coeffs {
-		if c <= groupUseCoff {
-			closestCoeff = c
+	receivers := make([]electricReceiver, 0, count)
+	for i := 0; i < count; i++ {
+		e := electricReceiver{Nu: nu[i], Cos: cos[i], Uh: Uh[i], N: n[i], Ph: Ph[i], KB: KB[i]}
+		if i < len(naming) {
+			e.Naming = naming[i]
+		}
+		// ЕП без кількості або потужності не додає навантаження, тому такий рядок вважаємо помилкою
+		switch {
+		case e.N <= 0 || e.N != math.Trunc(e.N):
+			return nil, fmt.Errorf("рядок %d: кількість n має бути цілим числом, більшим за 0", i+1)
+		case e.Ph <= 0:
+			return nil, fmt.Errorf("рядок %d: потужність Pн має бути більшою за 0", i+1)
+		case e.Uh <= 0 || e.Cos <= 0 || e.Cos > 1 || e.Nu <= 0 || e.KB < 0:
+			return nil, fmt.Errorf("рядок %d: некоректні параметри ЕП", i+1)
+		}
+		// Якщо коефіцієнт реактивної потужності не задано, знаходимо його з cos φ,
+		// щоб не втратити реактивне навантаження ЕП
+		value := ""
+		if i < len(tg) {
+			value = strings.TrimSpace(strings.ReplaceAll(tg[i], ",", "."))
+		}
+		if value == "" || value == "-" {
+			e.Tg = e.tgFromCos()
+			e.TgDerived = true
 		} else {
-			break
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil || f < 0 || math.IsNaN(f) || math.IsInf(f, 0) {
+				return nil, fmt.Errorf("рядок %d: некоректний tg φ", i+1)
+			}
+			e.Tg = f
+		}
+		receivers = append(receivers, e)
+	}
+	return receivers, nil
+}
+
+// Метод, що розподіляє ЕП по ШР за полем cabinet[] у порядку першої появи шафи у формі
+func groupReceiversByCabinet(r *http.Request, receivers []electricReceiver) ([][]electricReceiver, error) {
+	cabinets := r.Form["cabinet[]"]
+	if len(cabinets) != len(receivers) {
+		return nil, errors.New("every receiver must belong to a cabinet")
+	}
+	var order []string
+	byCabinet := make(map[string][]electricReceiver)
+	for i, c := range cabinets {
+		if _, ok := byCabinet[c]; !ok {
+			order = append(order, c)
 		}
+		byCabinet[c] = append(byCabinet[c], receivers[i])
+	}
+	groups := make([][]electricReceiver, 0, len(order))
+	for _, c := range order {
+		groups = append(groups, byCabinet[c])
+	}
+	return groups, nil
+}
+
+const prac6ReceiversFile = "./instance/prac_6_receivers.json"
+
+// Типи ЕП у каталозі типових електроприймачів та їх назви для інтерфейсу
+var receiverTypes = map[string]string{
+	"machine_tool": "Верстати",
+	"fan":          "Вентилятори",
+	"pump":         "Насоси та компресори",
+	"furnace":      "Електропечі та нагрівальні установки",
+	"welding":      "Зварювальні установки",
+}
+
+// Порядок, в якому групи ЕП каталогу виводяться користувачу
+var receiverTypeOrder = []string{"machine_tool", "fan", "pump", "furnace", "welding"}
+
+// Типовий ЕП з каталогу з довідковими Кв, cos φ, tg φ та ККД
+type catalogReceiver struct {
+	Name string  `json:"name"`
+	Type string  `json:"type"`
+	Kv   float64 `json:"kv"`  // коефіцієнт використання
+	Cos  float64 `json:"cos"` // коефіцієнт потужності
+	Tg   float64 `json:"tg"`  // коефіцієнт реактивної потужності
+	Nu   float64 `json:"nu"`  // номінальний ККД
+}
+
+// Група ЕП каталогу одного типу
+type catalogReceiverGroup struct {
+	Type      string            `json:"type"`
+	Label     string            `json:"label"`
+	Receivers []catalogReceiver `json:"receivers"`
+}
+
+// Метод, що читає каталог типових ЕП з файлу
+func getPrac6Receivers() ([]catalogReceiver, error) {
+	file, err := os.Open(prac6ReceiversFile)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var receivers []catalogReceiver
+	if err := json.NewDecoder(file).Decode(&receivers); err != nil {
+		return nil, err
+	}
+	return receivers, nil
+}
+
+// Шлях, що повертає каталог типових ЕП, згрупований за типом (?type= - лише ЕП одного типу)
+func prac6ReceiversHandler(w http.ResponseWriter, r *http.Request) {
+	receivers, err := getPrac6Receivers()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	typeFilter := r.URL.Query().Get("type")
+	if _, ok := receiverTypes[typeFilter]; typeFilter != "" && !ok {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("unknown receiver type %q", typeFilter)})
+		return
 	}
-	coeffKey := fmt.Sprintf("%g", closestCoeff)
 
-	if val, ok := targetRow[coeffKey]; ok {
-		return val, nil
+	groups := []catalogReceiverGroup{}
+	for _, t := range receiverTypeOrder {
+		if typeFilter != "" && t != typeFilter {
+			continue
+		}
+		group := catalogReceiverGroup{Type: t, Label: receiverTypes[t]}
+		for _, e := range receivers {
+			if e.Type == t {
+				group.Receivers = append(group.Receivers, e)
+			}
+		}
+		if len(group.Receivers) > 0 {
+			groups = append(groups, group)
+		}
 	}
-	return 0, fmt.Errorf("Kp2 coeff not found")
+	writeJSON(w, http.StatusOK, map[string]interface{}{"groups": groups})
 }
 
 func prac6Task1(w http.ResponseWriter, r *http.Request) {
@@ -906,224 +5126,148 @@ func prac6Task1(w http.ResponseWriter, r *http.Request) {
 	if r.Method == http.MethodPost {
 		r.ParseForm()
 
-		// Допоміжна функція для парсингу списків
-		parseList := func(key string) []float64 {
-			return getFloatList(r, key)
+		// Отримуємо користувацький ввід для ЕП усіх ШР та розподіляємо їх по шафах
+		// Помилки в окремих рядках ЕП повертаємо користувачу з номером рядка
+		receivers, err := getReceiversFromForm(r, "")
+		rowErr := err
+		var cabinetReceivers [][]electricReceiver
+		if err == nil {
+			cabinetReceivers, err = groupReceiversByCabinet(r, receivers)
 		}
-
-		// Отримуємо користувацький ввід для ЕП першого ШР
-		nu := parseList("nu[]")
-		cos := parseList("cos[]")
-		Uh := parseList("Uh[]")
-		n := parseList("n[]")
-		Ph := parseList("Ph[]")
-		KB := parseList("KB[]")
-		tg := parseList("tg[]")
-
-		count := len(nu)
-
-		// Масиви для результатів
-		var nPh, Ip, nPhKB, nPhKBtg, nPhSquare []float64
-		var sum_nPh, sum_nPhKB, sum_nPhKBtg, sum_nPhSquare float64
-		var sum_n float64
-
-		// Шукаємо розрахункові струми на І рівні електропостачання
-		for i := 0; i < count; i++ {
-			// n * Ph
-			val_nPh := n[i] * Ph[i]
-			nPh = append(nPh, val_nPh)
-			sum_nPh += val_nPh
-
-			// Знаходимо розрахунковий струм кожного ЕП
-			val_Ip := val_nPh / (math.Sqrt(3) * Uh[i] * cos[i] * nu[i])
-			Ip = append(Ip, round(val_Ip, 2))
-
-			// n * Ph * KB
-			val_nPhKB := val_nPh * KB[i]
-			nPhKB = append(nPhKB, round(val_nPhKB, 2))
-			sum_nPhKB += val_nPhKB
-
-			// n * Ph * KB * tg
-			val_nPhKBtg := val_nPhKB * tg[i]
-			nPhKBtg = append(nPhKBtg, round(val_nPhKBtg, 2))
-			sum_nPhKBtg += val_nPhKBtg
-
-			// n * Ph^2
-			val_nPhSquare := n[i] * math.Pow(Ph[i], 2)
-			nPhSquare = append(nPhSquare, round(val_nPhSquare, 2))
-			sum_nPhSquare += val_nPhSquare
-
-			sum_n += n[i]
+		// Отримуємо користувацький ввід для крупних ЕП
+		var bigReceivers []electricReceiver
+		if err == nil {
+			bigReceivers, err = getReceiversFromForm(r, "_big")
+			if err != nil {
+				rowErr = fmt.Errorf("крупні ЕП, %v", err)
+			}
 		}
-
-		// Знаходимо груповий коефіцієнт використання
-		groupUseCoff := 0.0
-		if sum_nPh > 0 {
-			groupUseCoff = sum_nPhKB / sum_nPh
+		if err == nil && len(receivers)+len(bigReceivers) == 0 {
+			err = errors.New("no receivers")
 		}
-
-		// Знаходимо ефективну кількість ЕП
-		ne := 0
-		if sum_nPhSquare > 0 {
-			ne = int(math.Ceil(math.Pow(sum_nPh, 2) / sum_nPhSquare))
+		// Спосіб пошуку Кр у таблицях 3.3 та 3.4
+		kpMode := r.FormValue("kp_mode")
+		if kpMode != kpModeInterpolate {
+			kpMode = kpModeSnap
 		}
-
-		// Знаходимо розрахунковий коефіцієнт активної потужності по таблиці 3.3
-		// за допомогою методу, описаного раніше в utils.py
-		Kp, err := getKp1(ne, groupUseCoff)
-		if err != nil {
-			Kp = 0
-			fmt.Println("Error finding Kp1:", err)
+		// Загальне навантаження цеху можна задати вручну (наприклад, якщо в таблиці наведено не всі ЕП цеху)
+		override := r.FormValue("override") != ""
+		allValues := defaultValues["all"]
+		var manual *receiverGroup
+		if err == nil && override {
+			manual = &receiverGroup{}
+			for _, field := range []struct {
+				key string
+				dst *float64
+			}{
+				{"n", &manual.N},
+				{"nPh", &manual.NPh},
+				{"nPhKB", &manual.NPhKB},
+				{"nPhKBtg", &manual.NPhKBtg},
+				{"nPh_square", &manual.NPhSquare},
+			} {
+				if *field.dst, err = getFloat(r, field.key); err != nil || *field.dst < 0 {
+					err = fmt.Errorf("invalid shop total %s", field.key)
+					break
+				}
+				// Кількість ЕП - ціле число, дробову частину не відкидаємо, а вважаємо помилкою вводу
+				if field.key == "n" && manual.N != math.Trunc(manual.N) {
+					err = fmt.Errorf("shop total n must be an integer")
+					break
+				}
+			}
+			if err == nil {
+				allValues = map[string]interface{}{
+					"override": true, "n": manual.N, "nPh": manual.NPh, "nPhKB": manual.NPhKB,
+					"nPhKBtg": manual.NPhKBtg, "nPh_square": manual.NPhSquare,
+				}
+			}
 		}
-
-		// Знаходимо розрахункове активне навантаження
-		Pp := Kp * sum_nPhKB
-		// Знаходимо розрахункове реактивне навантаження
-		Qp := Kp * sum_nPhKBtg
-		// Знаходимо повну потужність
-		Sp := math.Sqrt(math.Pow(Pp, 2) + math.Pow(Qp, 2))
-
-		// Середня напруга для розрахунку загального струму
-		var sumUh float64
-		for _, v := range Uh {
-			sumUh += v
+		if rowErr != nil {
+			data.Error = "Bad receivers: " + rowErr.Error()
+			respond(w, r, "prac_6_task_1", data, "templates/prac_6_task_1.html")
+			return
 		}
-		meanUh := 0.38 // Значення за замовчуванням
-		if len(Uh) > 0 {
-			meanUh = sumUh / float64(len(Uh))
+		if err != nil {
+			data.Error = "Bad values: check inputs"
+			respond(w, r, "prac_6_task_1", data, "templates/prac_6_task_1.html")
+			return
 		}
 
-		// Знаходимо розрахунковий груповий струм ШР1
-		Ip_total := Pp / meanUh
-
-		// Отримуємо користувацький ввід для крупних ЕП
-		nu_big := parseList("nu_big[]")
-		cos_big := parseList("cos_big[]")
-		Uh_big := parseList("Uh_big[]")
-		n_big := parseList("n_big[]")
-		Ph_big := parseList("Ph_big[]")
-		KB_big := parseList("KB_big[]")
-		tg_big := parseList("tg_big[]")
-
-		var nPh_big, Ip_big, nPhKB_big, nPhKBtg_big, nPhSquare_big []float64
-
-		// Розрахунки ті ж самі, що і для звичайних ЕП
-		for i := 0; i < len(nu_big); i++ {
-			v_nPh := n_big[i] * Ph_big[i]
-			nPh_big = append(nPh_big, v_nPh)
-
-			v_nPhKB := v_nPh * KB_big[i]
-			nPhKB_big = append(nPhKB_big, round(v_nPhKB, 2))
-
-			// Для другого ЕП відсутнє значення коефіцієнту реактивної потужності відсутнє,
-			// тому замість нього пишемо 0 (Це ніяк не вплине на розрахунки, зроблено тільки для зручності
-			v_tg := 0.0
-			if i < len(tg_big) {
-				v_tg = tg_big[i]
+		// Шукаємо розрахункові навантаження кожного ШР
+		var cabinetResults []map[string]interface{}
+		var cabinetValues []interface{}
+		var kpWarnings []string
+		for i, list := range cabinetReceivers {
+			cabinet, err := calcCabinetLoad(fmt.Sprintf("ШР %d", i+1), list, kpMode)
+			if err != nil {
+				kpWarnings = append(kpWarnings, fmt.Sprintf("%s: не вдалося знайти Kp у таблиці 3.3 (%v), розрахункові навантаження не визначено", cabinet.Name, err))
 			}
-			v_nPhKBtg := v_nPhKB * v_tg
-			nPhKBtg_big = append(nPhKBtg_big, round(v_nPhKBtg, 2))
-
-			v_nPhSq := n_big[i] * math.Pow(Ph_big[i], 2)
-			nPhSquare_big = append(nPhSquare_big, round(v_nPhSq, 2))
-
-			v_Ip := v_nPh / (math.Sqrt(3) * Uh_big[i] * cos_big[i] * nu_big[i])
-			Ip_big = append(Ip_big, round(v_Ip, 2))
+			cabinetResults = append(cabinetResults, cabinet.results())
+			cabinetValues = append(cabinetValues, cabinet.values())
 		}
 
-		// Отримуємо користувацький ввід загального навантаження цеху
-		n_all, _ := getFloat(r, "n")
-		nPh_all, _ := getFloat(r, "nPh")
-		nPhKB_all, _ := getFloat(r, "nPhKB")
-		nPhKBtg_all, _ := getFloat(r, "nPhKBtg")
-		nPhSquare_all, _ := getFloat(r, "nPh_square")
+		// Крупні ЕП не входять до ШР, тому для них знаходимо лише показники кожного ЕП
+		big := newReceiverGroup("Крупні ЕП", bigReceivers)
+		bigResults := big.results()
 
-		// Знаходимо коефіцієнти використання цеху в цілому
-		groupUseCoffAll := 0.0
-		if nPh_all > 0 {
-			groupUseCoffAll = nPhKB_all / nPh_all
+		// Знаходимо навантаження цеху в цілому, підсумовуючи усі ЕП ШР та крупні ЕП
+		auto := newReceiverGroup("Цех", append(append([]electricReceiver{}, receivers...), bigReceivers...))
+		shop, warnings, err := calcShopLoad(auto, manual, kpMode)
+		if err != nil {
+			kpWarnings = append(kpWarnings, fmt.Sprintf("Цех: не вдалося знайти Kp у таблиці 3.4 (%v), розрахункові навантаження не визначено", err))
 		}
-
-		// Знаходимо ефективну кількість ЕП цеху в цілому
-		neAll := 0
-		if nPhSquare_all > 0 {
-			neAll = int(math.Round(math.Pow(nPh_all, 2) / nPhSquare_all))
+		warnings = append(warnings, kpWarnings...)
+
+		// Перевіряємо відповідність tg φ та cos φ усіх ЕП
+		var derivedCount, mismatchCount int
+		for _, e := range auto.Receivers {
+			if e.TgDerived {
+				derivedCount++
+			} else if !e.tgMatchesCos() {
+				mismatchCount++
+			}
 		}
-
-		// Знаходимо розрахунковий коефіцієнт активної потужності по таблиці 3.4
-		// за допомогою методу, описаного раніше в utils.py
-		KpAll, _ := getKp2(neAll, groupUseCoffAll)
-
-		// Знаходимо розрахункове активне навантаження на шинах 0,38 кВ ТП
-		PpAll := KpAll * nPhKB_all
-		// Знаходимо розрахункове реактивне навантаження на шинах 0,38 кВ ТП
-		QpAll := KpAll * nPhKBtg_all
-		// Знаходимо повну потужність на шинах 0,38 кВ ТП
-		SpAll := math.Sqrt(math.Pow(PpAll, 2) + math.Pow(QpAll, 2))
-
-		// Середня напруга (використовуємо напругу крупних ЕП як базу 0.38)
-		var sumUhBig float64
-		for _, v := range Uh_big {
-			sumUhBig += v
+		if mismatchCount > 0 {
+			warnings = append(warnings, fmt.Sprintf("Для %d ЕП задане значення tg φ не відповідає cos φ (поля позначено червоним), у розрахунку використано задане tg φ", mismatchCount))
 		}
-		meanUhBig := 0.38
-		if len(Uh_big) > 0 {
-			meanUhBig = sumUhBig / float64(len(Uh_big))
+		var notes []string
+		if derivedCount > 0 {
+			notes = append(notes, fmt.Sprintf("Для %d ЕП tg φ не задано, його розраховано з cos φ", derivedCount))
 		}
 
-		// Знаходимо розрахунковий груповий струм на шинах 0,38 кВ ТП
-		IpAll := PpAll / meanUhBig
-
 		// Заносимо усі результати у список
 		results := map[string]interface{}{
-			"nPh_list": nPh, "Ip_list": Ip, "nPhKB_list": nPhKB, "nPhKBtg_list": nPhKBtg, "nPh_square_list": nPhSquare,
-			"group_use_coff": round(groupUseCoff, 1),
-			"ne":             ne, "Kp": round(Kp, 2), "Pp": round(Pp, 2), "Qp": round(Qp, 2), "Sp": round(Sp, 2), "Ip": round(Ip_total, 2),
-			"N": int(sum_n), "nPh_sum": int(sum_nPh), "nPhKB_sum": round(sum_nPhKB, 2), "nPhKBtg_sum": round(sum_nPhKBtg, 2),
-			"nPh_square_sum":    round(sum_nPhSquare, 2),
-			"nPh_big_list":      nPh_big, "Ip_big_list": Ip_big, "nPhKB_big_list": nPhKB_big,
-			"nPhKBtg_big_list":  nPhKBtg_big, "nPh_square_big_list": nPhSquare_big,
-			"group_use_coff_all": round(groupUseCoffAll, 2), "ne_all": neAll, "Kp_all": round(KpAll, 2),
-			"Pp_all": round(PpAll, 2), "Qp_all": round(QpAll, 2), "Sp_all": round(SpAll, 2), "Ip_all": round(IpAll, 2),
+			"cabinets":     cabinetResults,
+			"nPh_big_list": bigResults["nPh_list"], "Ip_big_list": bigResults["Ip_list"], "nPhKB_big_list": bigResults["nPhKB_list"],
+			"nPhKBtg_big_list": bigResults["nPhKBtg_list"], "nPh_square_big_list": bigResults["nPh_square_list"],
+			"n_all": int(shop.N), "nPh_all": round(shop.NPh, 2), "nPhKB_all": round(shop.NPhKB, 2),
+			"nPhKBtg_all": round(shop.NPhKBtg, 2), "nPh_square_all": round(shop.NPhSquare, 2),
+			"n_auto": int(auto.N), "nPh_auto": round(auto.NPh, 2), "nPhKB_auto": round(auto.NPhKB, 2),
+			"nPhKBtg_auto": round(auto.NPhKBtg, 2), "nPh_square_auto": round(auto.NPhSquare, 2),
+			"override": override, "warnings": warnings, "notes": notes,
+			"group_use_coff_all": round(shop.GroupUseCoff, 2), "ne_all": shop.Ne,
+			// Результати недійсні, якщо хоча б для однієї групи не вдалося знайти Кр
+			"valid": len(kpWarnings) == 0,
+		}
+		if shop.Valid {
+			results["Kp_all"] = round(shop.Kp, 2)
+			results["Kp_all_cells"] = shop.KpCells
+			results["Pp_all"] = round(shop.Pp, 2)
+			results["Qp_all"] = round(shop.Qp, 2)
+			results["Sp_all"] = round(shop.Sp, 2)
+			results["Ip_all"] = round(shop.Ip, 2)
 		}
 		data.Results = results
 
-		// Також створюємо список, який позначає користувацьки ввід
-		// Це створено для того, щоб після розрахунків, значення введені користувачем, лишились
-		userValues := make(map[string]interface{})
-
-		normalMap := make(map[string]interface{})
-		normalMap["naming"] = defaultValues["normal"].(map[string]interface{})["naming"]
-		normalMap["nu[]"] = nu
-		normalMap["cos[]"] = cos
-		normalMap["Uh[]"] = Uh
-		normalMap["n[]"] = n
-		normalMap["Ph[]"] = Ph
-		normalMap["KB[]"] = KB
-		normalMap["tg[]"] = tg
-		userValues["normal"] = normalMap
-
-		bigMap := make(map[string]interface{})
-		bigMap["naming"] = defaultValues["big"].(map[string]interface{})["naming"]
-		bigMap["nu[]"] = nu_big
-		bigMap["cos[]"] = cos_big
-		bigMap["Uh[]"] = Uh_big
-		bigMap["n[]"] = n_big
-		bigMap["Ph[]"] = Ph_big
-		bigMap["KB[]"] = KB_big
-		bigMap["tg[]"] = tg_big
-		userValues["big"] = bigMap
-
-		allMap := make(map[string]interface{})
-		allMap["n"] = n_all
-		allMap["nPh"] = nPh_all
-		allMap["nPhKB"] = nPhKB_all
-		allMap["nPhKBtg"] = nPhKBtg_all
-		allMap["nPh_square"] = nPhSquare_all
-		userValues["all"] = allMap
-
-		data.DefaultValues = userValues
-	}
-
-	render(w, "prac_6_task_1", data, "templates/prac_6_task_1.html")
+		// Також створюємо список, який позначає користувацький ввід
+		data.DefaultValues = map[string]interface{}{
+			"cabinets": cabinetValues,
+			"big":      big.values(),
+			"all":      allValues,
+			"kp_mode":  kpMode,
+		}
+	}
+
+	respond(w, r, "prac_6_task_1", data, "templates/prac_6_task_1.html")
 }
\ No newline at end of file
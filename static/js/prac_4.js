@@ -0,0 +1,13 @@
+$(document).ready(function(){
+    // Заповнюємо параметри трансформатора для розрахунку початкового струму КЗ,
+    // коли користувач обирає трансформатор з каталогу
+    $('#transformer-select').on('change', function(){
+        var option = $(this).find('option:selected');
+        if (option.val() === '') {
+            return;
+        }
+        $('input[name="t_uk"]').val(option.data('uk'));
+        // Номінальна потужність у каталозі задана в кВ*А, а у формі - в МВ*А
+        $('input[name="t_snom"]').val(option.data('snom') / 1000);
+    });
+});
@@ -1,6 +1,8 @@
 $(document).ready(function(){
     var data; // Відповідає за дані з /prac-5/data
-    var element_count = 0; // Відповідає за те, скільки елементів ЕПС налічує сторінка
+    // Відповідає за те, скільки елементів ЕПС налічує сторінка (після відправки форми рядки повертає сервер)
+    var element_count = $('#dynamic-inputs .input-group').length;
+    updateButtonState();
     // Здійснюємо ajax запит, щоб отримати перелік доступних елементів ЕПС
     $.ajax({
         url: '/prac-5/data',
@@ -31,12 +33,16 @@ $(document).ready(function(){
                 '</div>');
         $('#dynamic-inputs').append(newItem); // Додаємо новий елемент до контейнера
         var selectElement = newItem.find('select');
-        // Додаємо опції для вибору елемента з отриманих даних
-        $.each(data, function(index, value) {
-            selectElement.append($('<option>', {
-                value: value,
-                text: value
-            }));
+        // Додаємо опції для вибору елемента з отриманих даних, згруповані за типом елемента
+        $.each(data.groups, function(index, group) {
+            var optgroup = $('<optgroup>', {label: group.label});
+            $.each(group.elements, function(i, element) {
+                optgroup.append($('<option>', {
+                    value: element.name,
+                    text: element.name + ' (ω=' + element.omega + ' ' + data.units.omega + ', tв=' + element.tv + ' ' + data.units.tv + ')'
+                }));
+            });
+            selectElement.append(optgroup);
         });
         element_count += 1;
         updateButtonState();
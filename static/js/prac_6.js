@@ -0,0 +1,173 @@
+$(document).ready(function(){
+    // Відповідає за ідентифікатор наступного ШР (після відправки форми шафи повертає сервер).
+    // Ідентифікатор лише групує ЕП однієї шафи, тому після видалення ШР його не перераховуємо
+    var cabinet_id = $('tbody.cabinet').length;
+    var catalog; // Відповідає за каталог типових ЕП з /prac-6/receivers
+    updateButtonState();
+
+    // Здійснюємо ajax запит, щоб отримати каталог типових ЕП
+    $.ajax({
+        url: '/prac-6/receivers',
+        type: 'GET',
+        success: function(response) {
+            catalog = response;
+            $('.catalog-select').each(function(){
+                fillCatalogSelect($(this));
+            });
+        },
+        error: function(xhr, status, error) {
+            console.error('Error:', status, error);
+        }
+    });
+
+    // Функція, що додає до списку вибору типові ЕП, згруповані за типом
+    function fillCatalogSelect(selectElement) {
+        if (!catalog) {
+            return;
+        }
+        $.each(catalog.groups, function(index, group) {
+            var optgroup = $('<optgroup>', {label: group.label});
+            $.each(group.receivers, function(i, receiver) {
+                optgroup.append($('<option>', {
+                    value: receiver.name,
+                    text: receiver.name + ' (Кв=' + receiver.kv + ', cos φ=' + receiver.cos + ')',
+                    data: receiver
+                }));
+            });
+            selectElement.append(optgroup);
+        });
+    }
+
+    // Функція, що створює рядок з полями для нового ЕП вказаного ШР.
+    // Для крупних ЕП cabinet дорівнює null, а назви полів мають суфікс _big (шафі вони не належать).
+    // Якщо передано типовий ЕП з каталогу, його параметри підставляються у відповідні поля
+    function newReceiverRow(cabinet, receiver) {
+        var suffix = cabinet === null ? '_big' : '';
+        var hidden = cabinet === null ? '' : '<input type="hidden" name="cabinet[]" value="' + cabinet + '">';
+        var row = $('<tr class="receiver">'+
+                '<td>'+
+                    hidden+
+                    '<i class="fa-solid fa-delete-left fa-xl mt-3 remove-receiver" style="color: #d41616;"></i>'+
+                '</td>'+
+                '<td><input name="naming' + suffix + '[]" class="form-control" required></td>'+
+                '<td><input name="nu' + suffix + '[]" class="form-control" value="0.92" required></td>'+
+                '<td><input name="cos' + suffix + '[]" class="form-control" value="0.9" required></td>'+
+                '<td><input name="Uh' + suffix + '[]" class="form-control" value="0.38" required></td>'+
+                '<td><input name="n' + suffix + '[]" class="form-control" value="1" required></td>'+
+                '<td><input name="Ph' + suffix + '[]" class="form-control" required></td>'+
+                '<td>-</td>'+
+                '<td><input name="KB' + suffix + '[]" class="form-control" required></td>'+
+                // tg φ можна не задавати, тоді сервер розрахує його з cos φ
+                '<td><input name="tg' + suffix + '[]" class="form-control" placeholder="з cos φ"></td>'+
+            '</tr>');
+        // Решта стовпців містить результати, які з'являться після розрахунку
+        for (var i = 0; i < 9; i++) {
+            row.append('<td>-</td>');
+        }
+        if (receiver) {
+            row.find('input[name="naming' + suffix + '[]"]').val(receiver.name);
+            row.find('input[name="nu' + suffix + '[]"]').val(receiver.nu);
+            row.find('input[name="cos' + suffix + '[]"]').val(receiver.cos);
+            row.find('input[name="KB' + suffix + '[]"]').val(receiver.kv);
+            row.find('input[name="tg' + suffix + '[]"]').val(receiver.tg);
+        }
+        return row;
+    }
+
+    // Функція, що додає рядок ЕП у кінець групи: перед підсумком ШР або перед навантаженням цеху для крупних ЕП
+    function appendReceiverRow(group, receiver) {
+        var row;
+        if (group.is('#big-receivers')) {
+            row = newReceiverRow(null, receiver);
+            group.find('tr.shop-total').before(row);
+        } else {
+            row = newReceiverRow(group.data('cabinet'), receiver);
+            group.find('tr.cabinet-total').before(row);
+        }
+        updateButtonState();
+        return row;
+    }
+
+    // Обробник події для додавання нового ЕП до ШР або до крупних ЕП
+    $('table').on('click', '.add-receiver', function(){
+        appendReceiverRow($(this).closest('tbody'));
+    });
+
+    // Обробник події для додавання типового ЕП з каталогу до ШР або до крупних ЕП
+    $('table').on('click', '.add-from-catalog', function(){
+        var group = $(this).closest('tbody');
+        var option = group.find('.catalog-select option:selected');
+        if (option.val() === '') {
+            return;
+        }
+        var row = appendReceiverRow(group, option.data());
+        // Користувачу лишається ввести лише номінальну потужність (та кількість, якщо вона більша за 1)
+        row.find('input[name^="Ph"]').focus();
+    });
+
+    // Обробник події для видалення ЕП
+    $('table').on('click', '.remove-receiver', function(){
+        $(this).closest('tr').remove();
+        updateButtonState();
+    });
+
+    // Обробник події для видалення ШР разом з усіма його ЕП
+    $('table').on('click', '.remove-cabinet', function(){
+        $(this).closest('tbody.cabinet').remove();
+        renumberCabinets();
+        updateButtonState();
+    });
+
+    // Обробник події для додавання нового ШР з одним порожнім ЕП
+    $('#add-cabinet').on('click', function(){
+        var cabinet = $('<tbody class="cabinet" data-cabinet="' + cabinet_id + '">'+
+                '<tr class="table-light">'+
+                    '<td colspan="19" class="text-start">'+
+                        '<strong class="cabinet-name"></strong>'+
+                        '<button type="button" class="btn btn-sm btn-outline-primary ms-3 add-receiver"><i class="fa-solid fa-plus"></i> Додати ЕП</button>'+
+                        '<select class="form-select form-select-sm d-inline-block w-auto ms-3 catalog-select">'+
+                            '<option value="">Оберіть типовий ЕП</option>'+
+                        '</select>'+
+                        '<button type="button" class="btn btn-sm btn-outline-primary ms-2 add-from-catalog"><i class="fa-solid fa-plus"></i> Додати з каталогу</button>'+
+                        '<button type="button" class="btn btn-sm btn-outline-danger ms-2 remove-cabinet"><i class="fa-solid fa-trash"></i> Видалити ШР</button>'+
+                    '</td>'+
+                '</tr>'+
+                '<tr class="cabinet-total">'+
+                    '<td colspan="2">ВСЬОГО <span class="cabinet-name"></span></td>'+
+                '</tr>'+
+            '</tbody>');
+        var total = cabinet.find('tr.cabinet-total');
+        for (var i = 0; i < 17; i++) {
+            total.append('<td>-</td>');
+        }
+        total.before(newReceiverRow(cabinet_id));
+        fillCatalogSelect(cabinet.find('.catalog-select'));
+        $('#big-receivers').before(cabinet);
+        cabinet_id += 1;
+        renumberCabinets();
+        updateButtonState();
+    });
+
+    // Обробник події для перемикання між автоматичним та ручним навантаженням цеху.
+    // Вимкнені поля не відправляються, тому сервер використає суми по ЕП
+    $('#override').on('change', function(){
+        $('.manual-total').prop('disabled', !this.checked);
+    });
+
+    // Функція, що оновлює назви ШР відповідно до їх порядку в таблиці (так само їх нумерує сервер)
+    function renumberCabinets() {
+        $('tbody.cabinet').each(function(index){
+            $(this).find('.cabinet-name').text('ШР ' + (index + 1));
+        });
+    }
+
+    // Функція для оновлення стану кнопки в залежності від кількості ЕП
+    // Зроблено для того, щоб користувач не міг відправити форму без жодного ЕП у ШР
+    function updateButtonState() {
+        if ($('tr.receiver').length > 0){
+            $('.btn-success').removeAttr('disabled');
+        } else {
+            $('.btn-success').attr('disabled','disabled');
+        }
+    }
+});